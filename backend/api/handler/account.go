@@ -0,0 +1,446 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"bytecast/api/middleware"
+	"bytecast/api/utils"
+	"bytecast/configs"
+	apperrors "bytecast/internal/errors"
+	"bytecast/internal/services"
+)
+
+type forgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+type verifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password"` // Empty for accounts without a password
+	NewPassword     string `json:"new_password" binding:"required,min=8"`
+}
+
+type changeEmailRequest struct {
+	CurrentPassword string `json:"current_password"`
+	Email           string `json:"email" binding:"required,email"`
+}
+
+type changeUsernameRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=24,alphanum"`
+}
+
+type deleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+type confirmTOTPRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+type passwordConfirmationRequest struct {
+	Password string `json:"password"`
+}
+
+type AccountHandler struct {
+	accountService *services.AccountService
+	auditService   *services.AuditService
+	config         *configs.Config
+}
+
+func NewAccountHandler(accountService *services.AccountService, auditService *services.AuditService, config *configs.Config) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+		auditService:   auditService,
+		config:         config,
+	}
+}
+
+func (h *AccountHandler) RegisterRoutes(r *gin.Engine, authMiddleware gin.HandlerFunc, limiter *middleware.RateLimiter) {
+	auth := r.Group("/api/v1/auth")
+	auth.Use(limiter.Limit(middleware.RateLimitAuth))
+	{
+		auth.POST("/password/forgot", h.forgotPassword)
+		auth.POST("/password/reset", h.resetPassword)
+		auth.POST("/email/verify", h.verifyEmail)
+		auth.POST("/email/verify/resend", authMiddleware, h.resendVerification)
+		auth.PUT("/me/password", authMiddleware, h.changePassword)
+		auth.PUT("/me/email", authMiddleware, h.changeEmail)
+		auth.PUT("/me/username", authMiddleware, h.changeUsername)
+		auth.GET("/me/export", authMiddleware, h.exportAccount)
+		auth.GET("/me/audit", authMiddleware, h.getAuditEvents)
+		auth.DELETE("/me", authMiddleware, h.deleteAccount)
+		auth.GET("/me/2fa", authMiddleware, h.twoFactorStatus)
+		auth.POST("/me/2fa/totp", authMiddleware, h.beginTOTPEnrollment)
+		auth.POST("/me/2fa/totp/confirm", authMiddleware, h.confirmTOTPEnrollment)
+		auth.POST("/me/2fa/recovery-codes", authMiddleware, h.regenerateRecoveryCodes)
+		auth.DELETE("/me/2fa", authMiddleware, h.disableTwoFactor)
+	}
+}
+
+func (h *AccountHandler) getUserID(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.HandleError(c, apperrors.NewUnauthorized("Not authenticated", nil))
+		return 0, false
+	}
+
+	return userID.(uint), true
+}
+
+func (h *AccountHandler) forgotPassword(c *gin.Context) {
+	var req forgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err, "Invalid input data")
+		return
+	}
+
+	if err := h.accountService.RequestPasswordReset(req.Email); err != nil {
+		utils.HandleError(c, utils.LogError("Failed to request password reset", err))
+		return
+	}
+
+	// Same response whether or not the address is registered
+	c.JSON(http.StatusAccepted, gin.H{
+		"status":  "success",
+		"message": "If an account exists for this email, a reset link has been sent",
+	})
+}
+
+func (h *AccountHandler) resetPassword(c *gin.Context) {
+	var req resetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err, "Invalid input data")
+		return
+	}
+
+	userID, err := h.accountService.ResetPassword(req.Token, req.Password)
+	if err != nil {
+		var appErr apperrors.AppError
+
+		switch err {
+		case services.ErrInvalidUserToken:
+			appErr = apperrors.NewBadRequest("This reset link is invalid or has expired", err)
+		default:
+			appErr = utils.LogError("Failed to reset password", err)
+		}
+
+		utils.HandleError(c, appErr)
+		return
+	}
+
+	recordAudit(c, h.auditService, services.AuditEntry{UserID: userID, ActorID: userID, Action: services.AuditPasswordReset})
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Password has been reset. Please log in with your new password",
+	})
+}
+
+func (h *AccountHandler) verifyEmail(c *gin.Context) {
+	var req verifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err, "Invalid input data")
+		return
+	}
+
+	if err := h.accountService.VerifyEmail(req.Token); err != nil {
+		var appErr apperrors.AppError
+
+		switch err {
+		case services.ErrInvalidUserToken:
+			appErr = apperrors.NewBadRequest("This verification link is invalid or has expired", err)
+		default:
+			appErr = utils.LogError("Failed to verify email", err)
+		}
+
+		utils.HandleError(c, appErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Email address verified",
+	})
+}
+
+func (h *AccountHandler) resendVerification(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.accountService.SendVerificationEmail(userID); err != nil {
+		var appErr apperrors.AppError
+
+		switch {
+		case errors.Is(err, services.ErrEmailAlreadyVerified):
+			appErr = apperrors.NewConflict("Your email address is already verified", err)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			appErr = apperrors.NewNotFound("User not found", err)
+		default:
+			appErr = utils.LogError("Failed to send verification email", err)
+		}
+
+		utils.HandleError(c, appErr)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"status":  "success",
+		"message": "Verification email sent",
+	})
+}
+
+func (h *AccountHandler) changePassword(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req changePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err, "Invalid input data")
+		return
+	}
+
+	if err := h.accountService.ChangePassword(userID, c.GetString("session_id"), req.CurrentPassword, req.NewPassword); err != nil {
+		utils.HandleError(c, h.settingsError("Failed to change password", err))
+		return
+	}
+
+	recordAudit(c, h.auditService, services.AuditEntry{UserID: userID, ActorID: userID, Action: services.AuditPasswordChanged})
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Password changed. Other sessions have been signed out",
+	})
+}
+
+func (h *AccountHandler) changeEmail(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req changeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err, "Invalid input data")
+		return
+	}
+
+	if err := h.accountService.ChangeEmail(userID, c.GetString("session_id"), req.CurrentPassword, req.Email); err != nil {
+		utils.HandleError(c, h.settingsError("Failed to change email", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Email changed. Check your inbox to verify the new address",
+	})
+}
+
+func (h *AccountHandler) changeUsername(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req changeUsernameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err, "Invalid input data")
+		return
+	}
+
+	user, err := h.accountService.ChangeUsername(userID, req.Username)
+	if err != nil {
+		utils.HandleError(c, h.settingsError("Failed to change username", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user": gin.H{
+			"id":       user.ID,
+			"username": user.Username,
+			"email":    user.Email,
+		},
+	})
+}
+
+func (h *AccountHandler) exportAccount(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	export, err := h.accountService.ExportAccount(userID)
+	if err != nil {
+		utils.HandleError(c, h.settingsError("Failed to export account data", err))
+		return
+	}
+
+	filename := fmt.Sprintf("bytecast-export-%s.json", export.ExportedAt.Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Cache-Control", "no-store")
+	c.IndentedJSON(http.StatusOK, export)
+}
+
+func (h *AccountHandler) deleteAccount(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req deleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err, "Please confirm your password to delete your account")
+		return
+	}
+
+	if err := h.accountService.DeleteAccount(userID, req.Password); err != nil {
+		utils.HandleError(c, h.settingsError("Failed to delete account", err))
+		return
+	}
+
+	secure := h.config.Server.Environment == "production"
+	utils.ClearRefreshTokenCookie(c, secure, h.config.Server.Domain)
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AccountHandler) twoFactorStatus(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.accountService.TwoFactorStatus(userID)
+	if err != nil {
+		utils.HandleError(c, h.settingsError("Failed to retrieve two-factor status", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+func (h *AccountHandler) beginTOTPEnrollment(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.accountService.BeginTOTPEnrollment(userID)
+	if err != nil {
+		utils.HandleError(c, h.settingsError("Failed to start two-factor enrollment", err))
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, enrollment)
+}
+
+func (h *AccountHandler) confirmTOTPEnrollment(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req confirmTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err, "Enter the 6-digit code from your authenticator app")
+		return
+	}
+
+	codes, err := h.accountService.ConfirmTOTPEnrollment(userID, req.Code)
+	if err != nil {
+		utils.HandleError(c, h.settingsError("Failed to enable two-factor authentication", err))
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"recovery_codes": codes,
+	})
+}
+
+func (h *AccountHandler) regenerateRecoveryCodes(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req passwordConfirmationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err, "Please confirm your password")
+		return
+	}
+
+	codes, err := h.accountService.RegenerateRecoveryCodes(userID, req.Password)
+	if err != nil {
+		utils.HandleError(c, h.settingsError("Failed to regenerate recovery codes", err))
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"recovery_codes": codes,
+	})
+}
+
+func (h *AccountHandler) disableTwoFactor(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req passwordConfirmationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err, "Please confirm your password")
+		return
+	}
+
+	if err := h.accountService.DisableTwoFactor(userID, req.Password); err != nil {
+		utils.HandleError(c, h.settingsError("Failed to disable two-factor authentication", err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// settingsError maps errors of the account settings endpoints
+func (h *AccountHandler) settingsError(message string, err error) apperrors.AppError {
+	switch {
+	case errors.Is(err, services.ErrIncorrectPassword):
+		return apperrors.NewBadRequest("Current password is incorrect", err)
+	case errors.Is(err, services.ErrReauthenticationRequired):
+		return apperrors.NewForbidden("Sign in with your identity provider again to confirm this change", err)
+	case errors.Is(err, services.ErrUserExists):
+		return apperrors.NewConflict("This email is already registered", err)
+	case errors.Is(err, services.ErrUsernameTaken):
+		return apperrors.NewConflict("This username is already taken", err)
+	case errors.Is(err, services.ErrTwoFactorEnabled):
+		return apperrors.NewConflict("Two-factor authentication is already enabled", err)
+	case errors.Is(err, services.ErrTwoFactorNotEnabled):
+		return apperrors.NewConflict("Two-factor authentication is not enabled", err)
+	case errors.Is(err, services.ErrTOTPEnrollmentMissing):
+		return apperrors.NewConflict("Start two-factor enrollment first", err)
+	case errors.Is(err, services.ErrInvalidTwoFactorCode):
+		return apperrors.NewBadRequest("Invalid authentication code", err)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return apperrors.NewNotFound("User not found", err)
+	default:
+		return utils.LogError(message, err)
+	}
+}
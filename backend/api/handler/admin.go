@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"bytecast/api/utils"
+	apperrors "bytecast/internal/errors"
+	"bytecast/internal/models"
+	"bytecast/internal/services"
+)
+
+type listUsersQuery struct {
+	Query    string `form:"q" binding:"max=255"`
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PageSize int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+type adminUserResponse struct {
+	ID               uint        `json:"id"`
+	Username         string      `json:"username"`
+	Email            string      `json:"email"`
+	Role             models.Role `json:"role"`
+	EmailVerified    bool        `json:"email_verified"`
+	TwoFactorEnabled bool        `json:"two_factor_enabled"`
+	DisabledAt       *time.Time  `json:"disabled_at"`
+	CreatedAt        time.Time   `json:"created_at"`
+}
+
+type adminWatchlistResponse struct {
+	watchlistResponse
+	OwnerID  uint              `json:"owner_id"`
+	Channels []channelResponse `json:"channels"`
+}
+
+func adminUserToResponse(user *models.User) adminUserResponse {
+	return adminUserResponse{
+		ID:               user.ID,
+		Username:         user.Username,
+		Email:            user.Email,
+		Role:             user.Role,
+		EmailVerified:    user.EmailVerifiedAt != nil,
+		TwoFactorEnabled: user.TOTPEnabledAt != nil,
+		DisabledAt:       user.DisabledAt,
+		CreatedAt:        user.CreatedAt,
+	}
+}
+
+type AdminHandler struct {
+	adminService *services.AdminService
+	auditService *services.AuditService
+}
+
+func NewAdminHandler(adminService *services.AdminService, auditService *services.AuditService) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+		auditService: auditService,
+	}
+}
+
+// RegisterRoutes mounts the admin API. requireAdmin runs after authMiddleware
+// and rejects everyone without the admin role.
+func (h *AdminHandler) RegisterRoutes(r *gin.Engine, authMiddleware, requireAdmin gin.HandlerFunc) {
+	admin := r.Group("/api/v1/admin")
+	admin.Use(authMiddleware, requireAdmin)
+
+	admin.GET("/users", h.listUsers)
+	admin.GET("/audit", h.listAuditEvents)
+	admin.POST("/users/:id/disable", h.disableUser)
+	admin.POST("/users/:id/enable", h.enableUser)
+	admin.GET("/watchlists/:id", h.getWatchlist)
+	admin.POST("/channels/resubscribe", h.resubscribeAllChannels)
+	admin.POST("/channels/:channel_id/resubscribe", h.resubscribeChannel)
+	admin.GET("/invites", h.listInviteCodes)
+	admin.POST("/invites", h.createInviteCode)
+	admin.DELETE("/invites/:id", h.revokeInviteCode)
+}
+
+func (h *AdminHandler) listUsers(c *gin.Context) {
+	var query listUsersQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.HandleValidationError(c, err, "Invalid query parameters")
+		return
+	}
+
+	page, err := h.adminService.ListUsers(query.Query, query.Page, query.PageSize)
+	if err != nil {
+		utils.HandleError(c, utils.LogError("Failed to list users", err))
+		return
+	}
+
+	users := make([]adminUserResponse, len(page.Users))
+	for i := range page.Users {
+		users[i] = adminUserToResponse(&page.Users[i])
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users":     users,
+		"total":     page.Total,
+		"page":      page.Page,
+		"page_size": page.PageSize,
+	})
+}
+
+func (h *AdminHandler) disableUser(c *gin.Context) {
+	h.setUserDisabled(c, true)
+}
+
+func (h *AdminHandler) enableUser(c *gin.Context) {
+	h.setUserDisabled(c, false)
+}
+
+func (h *AdminHandler) setUserDisabled(c *gin.Context, disabled bool) {
+	adminID := c.GetUint("user_id")
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.HandleError(c, apperrors.NewBadRequest("Invalid user ID", err))
+		return
+	}
+
+	user, err := h.adminService.SetUserDisabled(adminID, uint(userID), disabled)
+	if err != nil {
+		var appErr apperrors.AppError
+
+		switch err {
+		case services.ErrUserNotFound:
+			appErr = apperrors.NewNotFound("User not found", err)
+		case services.ErrCannotDisableSelf:
+			appErr = apperrors.NewBadRequest("You cannot disable your own account", err)
+		default:
+			appErr = utils.LogError("Failed to update user", err)
+		}
+
+		utils.HandleError(c, appErr)
+		return
+	}
+
+	action := services.AuditUserEnabled
+	if disabled {
+		action = services.AuditUserDisabled
+	}
+	recordAudit(c, h.auditService, services.AuditEntry{UserID: user.ID, ActorID: adminID, Action: action})
+
+	c.JSON(http.StatusOK, adminUserToResponse(user))
+}
+
+func (h *AdminHandler) getWatchlist(c *gin.Context) {
+	watchlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.HandleError(c, apperrors.NewBadRequest("Invalid watchlist ID", err))
+		return
+	}
+
+	watchlist, err := h.adminService.GetWatchlist(uint(watchlistID))
+	if err != nil {
+		switch err {
+		case services.ErrWatchlistNotFound:
+			utils.HandleError(c, apperrors.NewNotFound("Watchlist not found", err))
+		default:
+			utils.HandleError(c, utils.LogError("Failed to retrieve watchlist", err))
+		}
+		return
+	}
+
+	channels := make([]channelResponse, len(watchlist.Channels))
+	for i, channel := range watchlist.Channels {
+		channels[i] = channelToResponse(channel)
+	}
+
+	c.JSON(http.StatusOK, adminWatchlistResponse{
+		watchlistResponse: watchlistToResponse(watchlist),
+		OwnerID:           watchlist.UserID,
+		Channels:          channels,
+	})
+}
+
+func (h *AdminHandler) resubscribeChannel(c *gin.Context) {
+	if err := h.adminService.ResubscribeChannel(c.Param("channel_id")); err != nil {
+		switch err {
+		case services.ErrChannelNotFound:
+			utils.HandleError(c, apperrors.NewNotFound("Channel not found", err))
+		case services.ErrPubSubDisabled:
+			utils.HandleError(c, apperrors.NewServiceUnavailable("Push notifications are not configured", err))
+		default:
+			utils.HandleError(c, utils.LogError("Failed to resubscribe channel", err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "Subscription renewed",
+	})
+}
+
+// resubscribeAllChannels reports partial failures in the log and still
+// returns how many channels were resubscribed
+func (h *AdminHandler) resubscribeAllChannels(c *gin.Context) {
+	resubscribed, err := h.adminService.ResubscribeAllChannels()
+	if errors.Is(err, services.ErrPubSubDisabled) {
+		utils.HandleError(c, apperrors.NewServiceUnavailable("Push notifications are not configured", err))
+		return
+	}
+	if err != nil {
+		log.Printf("Warning: Resubscribing channels partially failed: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":       "success",
+		"resubscribed": resubscribed,
+		"failed":       err != nil,
+	})
+}
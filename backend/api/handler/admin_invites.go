@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"bytecast/api/utils"
+	apperrors "bytecast/internal/errors"
+	"bytecast/internal/services"
+)
+
+type createInviteRequest struct {
+	Note          string `json:"note" binding:"max=100"`
+	MaxUses       *int   `json:"max_uses" binding:"omitempty,min=0,max=10000"` // Defaults to one use; zero is unlimited
+	ExpiresInDays int    `json:"expires_in_days" binding:"omitempty,min=1,max=365"`
+}
+
+func (h *AdminHandler) listInviteCodes(c *gin.Context) {
+	invites, err := h.adminService.ListInviteCodes()
+	if err != nil {
+		utils.HandleError(c, utils.LogError("Failed to list invite codes", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, invites)
+}
+
+func (h *AdminHandler) createInviteCode(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+
+	var req createInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err, "Invalid input data")
+		return
+	}
+
+	maxUses := 1
+	if req.MaxUses != nil {
+		maxUses = *req.MaxUses
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresInDays > 0 {
+		t := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		expiresAt = &t
+	}
+
+	invite, raw, err := h.adminService.CreateInviteCode(adminID, req.Note, maxUses, expiresAt)
+	if err != nil {
+		utils.HandleError(c, utils.LogError("Failed to create invite code", err))
+		return
+	}
+
+	// Like access tokens, the plaintext code is shown once and never stored
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, gin.H{
+		"code":        raw,
+		"invite_code": invite,
+	})
+}
+
+func (h *AdminHandler) revokeInviteCode(c *gin.Context) {
+	inviteID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.HandleError(c, apperrors.NewBadRequest("Invalid invite code ID", err))
+		return
+	}
+
+	if err := h.adminService.RevokeInviteCode(uint(inviteID)); err != nil {
+		switch err {
+		case services.ErrInviteCodeNotFound:
+			utils.HandleError(c, apperrors.NewNotFound("Invite code not found", err))
+		default:
+			utils.HandleError(c, utils.LogError("Failed to revoke invite code", err))
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
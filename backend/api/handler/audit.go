@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"bytecast/api/utils"
+	"bytecast/internal/services"
+)
+
+type auditEventsQuery struct {
+	Action   string `form:"action" binding:"max=64"`
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PageSize int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+type adminAuditEventsQuery struct {
+	auditEventsQuery
+	UserID uint `form:"user_id"`
+}
+
+// recordAudit records the event with the client details of the request
+func recordAudit(c *gin.Context, audit *services.AuditService, entry services.AuditEntry) {
+	entry.Client = clientInfo(c)
+	audit.Record(entry)
+}
+
+// recordWatchlistEvent records a change the user made to a watchlist
+func (h *WatchlistHandler) recordWatchlistEvent(c *gin.Context, userID uint, action string, watchlistID uint, detail string) {
+	recordAudit(c, h.auditService, services.AuditEntry{
+		UserID:     userID,
+		ActorID:    userID,
+		Action:     action,
+		TargetType: "watchlist",
+		TargetID:   strconv.FormatUint(uint64(watchlistID), 10),
+		Detail:     detail,
+	})
+}
+
+func auditPageResponse(c *gin.Context, page *services.AuditPage) {
+	c.JSON(http.StatusOK, gin.H{
+		"events":    page.Events,
+		"total":     page.Total,
+		"page":      page.Page,
+		"page_size": page.PageSize,
+	})
+}
+
+// getAuditEvents lists the signed in user's own audit events
+func (h *AccountHandler) getAuditEvents(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var query auditEventsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.HandleValidationError(c, err, "Invalid query parameters")
+		return
+	}
+
+	filter := services.AuditFilter{UserID: userID, Action: query.Action}
+	page, err := h.auditService.ListEvents(filter, query.Page, query.PageSize)
+	if err != nil {
+		utils.HandleError(c, utils.LogError("Failed to retrieve audit events", err))
+		return
+	}
+
+	auditPageResponse(c, page)
+}
+
+// listAuditEvents lists audit events of every user, or of user_id
+func (h *AdminHandler) listAuditEvents(c *gin.Context) {
+	var query adminAuditEventsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.HandleValidationError(c, err, "Invalid query parameters")
+		return
+	}
+
+	filter := services.AuditFilter{UserID: query.UserID, Action: query.Action}
+	page, err := h.auditService.ListEvents(filter, query.Page, query.PageSize)
+	if err != nil {
+		utils.HandleError(c, utils.LogError("Failed to retrieve audit events", err))
+		return
+	}
+
+	auditPageResponse(c, page)
+}
@@ -2,7 +2,11 @@ package handler
 
 import (
 	"errors"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -11,13 +15,15 @@ import (
 	"bytecast/api/utils"
 	"bytecast/configs"
 	apperrors "bytecast/internal/errors"
+	"bytecast/internal/models"
 	"bytecast/internal/services"
 )
 
 type registerRequest struct {
-    Email    string `json:"email" binding:"required,email"`
-    Username string `json:"username" binding:"required,min=3,max=24,alphanum"`
-    Password string `json:"password" binding:"required,min=8"`
+    Email      string `json:"email" binding:"required,email"`
+    Username   string `json:"username" binding:"required,min=3,max=24,alphanum"`
+    Password   string `json:"password" binding:"required,min=8"`
+    InviteCode string `json:"invite_code" binding:"max=64"` // Only checked when registration is invite-only
 }
 
 type loginRequest struct {
@@ -25,31 +31,52 @@ type loginRequest struct {
     Password   string `json:"password" binding:"required,min=6"`
 }
 
+type twoFactorLoginRequest struct {
+    ChallengeToken string `json:"challenge_token" binding:"required"`
+    Code           string `json:"code" binding:"required"`
+}
+
 type AuthHandler struct {
-    authService   *services.AuthService
-    config        *configs.Config
-    authMiddleware gin.HandlerFunc
+    authService    *services.AuthService
+    accountService *services.AccountService
+    auditService   *services.AuditService
+    config         *configs.Config
 }
 
-func NewAuthHandler(authService *services.AuthService, config *configs.Config) *AuthHandler {
+func NewAuthHandler(authService *services.AuthService, accountService *services.AccountService, auditService *services.AuditService, config *configs.Config) *AuthHandler {
     return &AuthHandler{
         authService:    authService,
+        accountService: accountService,
+        auditService:   auditService,
         config:         config,
-        authMiddleware: middleware.AuthMiddleware([]byte(config.JWT.Secret)),
     }
 }
 
-func (h *AuthHandler) RegisterRoutes(r *gin.Engine) {
+func (h *AuthHandler) RegisterRoutes(r *gin.Engine, authMiddleware gin.HandlerFunc, limiter *middleware.RateLimiter) {
     auth := r.Group("/api/v1/auth")
+    auth.Use(limiter.Limit(middleware.RateLimitAuth))
 	{
+        auth.GET("/registration", h.registration)
         auth.POST("/register", h.register)
         auth.POST("/login", h.login)
+        auth.POST("/login/2fa", h.loginTwoFactor)
         auth.POST("/refresh", h.refresh)
         auth.POST("/logout", h.logout)
-        auth.GET("/me", h.authMiddleware, h.me)
+        auth.GET("/me", authMiddleware, h.me)
+        auth.GET("/sessions", authMiddleware, h.getSessions)
+        auth.DELETE("/sessions/:id", authMiddleware, h.revokeSession)
+        auth.POST("/logout-all", authMiddleware, h.logoutAll)
+        auth.GET("/tokens", authMiddleware, h.getPersonalTokens)
+        auth.POST("/tokens", authMiddleware, h.createPersonalToken)
+        auth.DELETE("/tokens/:id", authMiddleware, h.revokePersonalToken)
     }
 }
 
+// registration tells the sign-up page whether to ask for an invite code
+func (h *AuthHandler) registration(c *gin.Context) {
+    c.JSON(http.StatusOK, gin.H{"mode": h.authService.RegistrationMode()})
+}
+
 func (h *AuthHandler) register(c *gin.Context) {
     var req registerRequest
     if err := c.ShouldBindJSON(&req); err != nil {
@@ -57,10 +84,16 @@ func (h *AuthHandler) register(c *gin.Context) {
         return
     }
 
-    if err := h.authService.RegisterUser(req.Email, req.Username, req.Password); err != nil {
+    if err := h.authService.RegisterUser(req.Email, req.Username, req.Password, req.InviteCode); err != nil {
         var appErr apperrors.AppError
         
         switch err {
+        case services.ErrRegistrationClosed:
+            appErr = apperrors.NewForbidden("Registration is closed", err)
+        case services.ErrInviteCodeRequired:
+            appErr = apperrors.NewForbidden("An invite code is required to register", err)
+        case services.ErrInviteCodeInvalid:
+            appErr = apperrors.NewForbidden("This invite code is invalid or has expired", err)
         case services.ErrUserExists:
             appErr = apperrors.NewConflict("This email is already registered", err)
         case services.ErrUsernameTaken:
@@ -74,13 +107,20 @@ func (h *AuthHandler) register(c *gin.Context) {
     }
 
 	// After successful registration, perform login to generate tokens
-	tokens, exp, err := h.authService.LoginUser(req.Email, req.Password)
+	tokens, exp, err := h.authService.LoginUser(req.Email, req.Password, clientInfo(c))
 	if err != nil {
 		appErr := apperrors.NewInternal("Failed to create account. Please try again", err)
 		utils.HandleError(c, appErr)
 		return
 	}
 
+	// A failed verification email must not fail the registration; it can be resent
+	if user, err := h.authService.FindByIdentifier(req.Email); err == nil {
+		if err := h.accountService.SendVerificationEmail(user.ID); err != nil {
+			log.Printf("Warning: Failed to send verification email to user %d: %v", user.ID, err)
+		}
+	}
+
     secure := h.config.Server.Environment == "production"
     utils.SetRefreshTokenCookie(c, tokens.RefreshToken, exp, secure, h.config.Server.Domain)
     
@@ -97,12 +137,27 @@ func (h *AuthHandler) login(c *gin.Context) {
         return
     }
 
-    tokens, exp, err := h.authService.LoginUser(req.Identifier, req.Password)
+    tokens, exp, err := h.authService.LoginUser(req.Identifier, req.Password, clientInfo(c))
     if err != nil {
         var appErr apperrors.AppError
+        var throttled *services.LoginThrottledError
+        var challenge *services.TwoFactorRequiredError
         
-        if err == services.ErrInvalidCredentials {
+        if errors.As(err, &challenge) {
+            c.JSON(http.StatusOK, gin.H{
+                "two_factor_required": true,
+                "challenge_token": challenge.ChallengeToken,
+                "expires_at": challenge.ExpiresAt.Unix(),
+            })
+            return
+        } else if err == services.ErrInvalidCredentials {
+            h.recordFailedLogin(c, req.Identifier)
             appErr = apperrors.NewUnauthorized("Invalid username/email or password", err)
+        } else if err == services.ErrAccountDisabled {
+            h.recordFailedLogin(c, req.Identifier)
+            appErr = apperrors.NewForbidden("This account has been disabled", err)
+        } else if errors.As(err, &throttled) {
+            appErr = h.throttledError(c, throttled)
         } else {
             appErr = utils.LogError("Authentication failed", err)
         }
@@ -117,6 +172,60 @@ func (h *AuthHandler) login(c *gin.Context) {
         return
     }
 
+    recordAudit(c, h.auditService, services.AuditEntry{UserID: user.ID, ActorID: user.ID, Action: services.AuditLogin})
+    h.loginResponse(c, tokens, exp, user)
+}
+
+// recordFailedLogin attributes a failed login to the account it was aimed at,
+// when there is one, and keeps the identifier that was tried
+func (h *AuthHandler) recordFailedLogin(c *gin.Context, identifier string) {
+    entry := services.AuditEntry{Action: services.AuditLoginFailed, Detail: identifier}
+    if user, err := h.authService.FindByIdentifier(identifier); err == nil {
+        entry.UserID = user.ID
+    }
+    recordAudit(c, h.auditService, entry)
+}
+
+// loginTwoFactor exchanges the challenge from login and a TOTP or recovery code for tokens
+func (h *AuthHandler) loginTwoFactor(c *gin.Context) {
+    var req twoFactorLoginRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        utils.HandleValidationError(c, err, "Invalid input data")
+        return
+    }
+
+    tokens, exp, user, err := h.authService.CompleteTwoFactorLogin(req.ChallengeToken, req.Code, clientInfo(c))
+    if err != nil {
+        var appErr apperrors.AppError
+        var throttled *services.LoginThrottledError
+        
+        switch {
+        case errors.Is(err, services.ErrTokenInvalid):
+            appErr = apperrors.NewUnauthorized("Login expired. Please log in again", err)
+        case errors.Is(err, services.ErrInvalidTwoFactorCode):
+            recordAudit(c, h.auditService, services.AuditEntry{
+                UserID: user.ID,
+                Action: services.AuditLoginFailed,
+                Detail: "invalid two-factor code",
+            })
+            appErr = apperrors.NewUnauthorized("Invalid authentication code", err)
+        case errors.Is(err, services.ErrAccountDisabled):
+            appErr = apperrors.NewForbidden("This account has been disabled", err)
+        case errors.As(err, &throttled):
+            appErr = h.throttledError(c, throttled)
+        default:
+            appErr = utils.LogError("Authentication failed", err)
+        }
+        
+        utils.HandleError(c, appErr)
+        return
+    }
+
+    recordAudit(c, h.auditService, services.AuditEntry{UserID: user.ID, ActorID: user.ID, Action: services.AuditLogin, Detail: "two-factor"})
+    h.loginResponse(c, tokens, exp, user)
+}
+
+func (h *AuthHandler) loginResponse(c *gin.Context, tokens *services.TokenPair, exp time.Time, user *models.User) {
     secure := h.config.Server.Environment == "production"
     utils.SetRefreshTokenCookie(c, tokens.RefreshToken, exp, secure, h.config.Server.Domain)
 
@@ -127,10 +236,17 @@ func (h *AuthHandler) login(c *gin.Context) {
 			"id": user.ID,
 			"username": user.Username,
 			"email": user.Email,
+			"role": user.Role,
 		},
     })
 }
 
+// throttledError sets Retry-After for a throttled login
+func (h *AuthHandler) throttledError(c *gin.Context, throttled *services.LoginThrottledError) apperrors.AppError {
+    c.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+    return apperrors.NewTooManyRequests("Too many failed login attempts. Please try again later", throttled)
+}
+
 func (h *AuthHandler) refresh(c *gin.Context) {
     refreshToken, err := c.Cookie("refresh_token")
     if err != nil {
@@ -138,17 +254,37 @@ func (h *AuthHandler) refresh(c *gin.Context) {
         return
     }
 
-    tokens, exp, err := h.authService.RefreshTokens(refreshToken)
+    if !utils.VerifyCSRFToken(c, refreshToken) {
+        utils.HandleError(c, apperrors.NewForbidden("Invalid or missing CSRF token", nil))
+        return
+    }
+
+    // Taken from the token before it is rotated, for the audit log
+    userID, _ := h.authService.RefreshTokenUserID(refreshToken)
+
+    tokens, exp, err := h.authService.RefreshTokens(refreshToken, clientInfo(c))
     if err != nil {
-        if err == services.ErrTokenInvalid {
+        secure := h.config.Server.Environment == "production"
+
+        switch err {
+        case services.ErrTokenInvalid, services.ErrTokenRevoked:
+            utils.ClearRefreshTokenCookie(c, secure, h.config.Server.Domain)
             utils.HandleError(c, apperrors.NewUnauthorized("Invalid session. Please log in again", err))
-            return
+        case services.ErrTokenReused:
+            recordAudit(c, h.auditService, services.AuditEntry{UserID: userID, Action: services.AuditTokenReused})
+            utils.ClearRefreshTokenCookie(c, secure, h.config.Server.Domain)
+            utils.HandleError(c, apperrors.NewUnauthorized("Session was ended for security reasons. Please log in again", err))
+        case services.ErrAccountDisabled:
+            utils.ClearRefreshTokenCookie(c, secure, h.config.Server.Domain)
+            utils.HandleError(c, apperrors.NewForbidden("This account has been disabled", err))
+        default:
+            utils.HandleError(c, utils.LogError("Failed to refresh session", err))
         }
-
-        utils.HandleError(c, utils.LogError("Failed to refresh session", err))
         return
     }
 
+    recordAudit(c, h.auditService, services.AuditEntry{UserID: userID, ActorID: userID, Action: services.AuditTokenRefreshed})
+
     secure := h.config.Server.Environment == "production"
     utils.SetRefreshTokenCookie(c, tokens.RefreshToken, exp, secure, h.config.Server.Domain)
     
@@ -165,9 +301,14 @@ func (h *AuthHandler) logout(c *gin.Context) {
         return
     }
 
+    if !utils.VerifyCSRFToken(c, refreshToken) {
+        utils.HandleError(c, apperrors.NewForbidden("Invalid or missing CSRF token", nil))
+        return
+    }
+
     if err := h.authService.RevokeToken(refreshToken); err != nil {
         switch err {
-        case services.ErrTokenInvalid:
+        case services.ErrTokenInvalid, services.ErrTokenRevoked:
             utils.HandleError(c, apperrors.NewUnauthorized("Invalid session", err))
         default:
             utils.HandleError(c, utils.LogError("Failed to log out", err))
@@ -175,6 +316,10 @@ func (h *AuthHandler) logout(c *gin.Context) {
         return
     }
 
+    if userID, ok := h.authService.RefreshTokenUserID(refreshToken); ok {
+        recordAudit(c, h.auditService, services.AuditEntry{UserID: userID, ActorID: userID, Action: services.AuditLogout})
+    }
+
     secure := h.config.Server.Environment == "production"
     utils.ClearRefreshTokenCookie(c, secure, h.config.Server.Domain)
 
@@ -185,18 +330,11 @@ func (h *AuthHandler) logout(c *gin.Context) {
 }
 
 func (h *AuthHandler) me(c *gin.Context) {
-    userID, exists := c.Get("userID")
-    if !exists {
-        utils.HandleError(c, apperrors.NewUnauthorized("Not authenticated", nil))
-        return
-    }
-
-    id, ok := userID.(uint)
+    id, ok := h.getUserID(c)
     if !ok {
-        utils.HandleError(c, utils.LogError("Invalid user ID format", nil))
         return
     }
-    
+
     user, err := h.authService.GetUserByID(id)
     if err != nil {
         if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -212,6 +350,33 @@ func (h *AuthHandler) me(c *gin.Context) {
             "id": user.ID,
             "username": user.Username,
             "email": user.Email,
+            "email_verified": user.EmailVerifiedAt != nil,
+            "two_factor_enabled": user.TOTPEnabledAt != nil,
+            "role": user.Role,
         },
     })
-}
\ No newline at end of file
+}
+
+func (h *AuthHandler) getUserID(c *gin.Context) (uint, bool) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        utils.HandleError(c, apperrors.NewUnauthorized("Not authenticated", nil))
+        return 0, false
+    }
+
+    id, ok := userID.(uint)
+    if !ok {
+        utils.HandleError(c, utils.LogError("Invalid user ID format", nil))
+        return 0, false
+    }
+
+    return id, true
+}
+
+func clientInfo(c *gin.Context) services.ClientInfo {
+    return services.ClientInfo{
+        UserAgent: c.Request.UserAgent(),
+        IPAddress: c.ClientIP(),
+        RequestID: c.GetString("requestID"),
+    }
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"bytecast/api/utils"
+	apperrors "bytecast/internal/errors"
+	"bytecast/internal/models"
+	"bytecast/internal/services"
+)
+
+type sessionResponse struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+func sessionToResponse(session *models.Session, currentID string) sessionResponse {
+	return sessionResponse{
+		ID:         session.ID,
+		UserAgent:  session.UserAgent,
+		IPAddress:  session.IPAddress,
+		Current:    session.ID == currentID,
+		CreatedAt:  session.CreatedAt,
+		LastUsedAt: session.LastUsedAt,
+		ExpiresAt:  session.ExpiresAt,
+	}
+}
+
+func (h *AuthHandler) getSessions(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.authService.GetSessions(userID)
+	if err != nil {
+		utils.HandleError(c, utils.LogError("Failed to retrieve sessions", err))
+		return
+	}
+
+	currentID := c.GetString("session_id")
+	response := make([]sessionResponse, len(sessions))
+	for i := range sessions {
+		response[i] = sessionToResponse(&sessions[i], currentID)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) revokeSession(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	sessionID := c.Param("id")
+	if err := h.authService.RevokeSession(userID, sessionID); err != nil {
+		var appErr apperrors.AppError
+
+		switch err {
+		case services.ErrSessionNotFound:
+			appErr = apperrors.NewNotFound("Session not found", err)
+		default:
+			appErr = utils.LogError("Failed to revoke session", err)
+		}
+
+		utils.HandleError(c, appErr)
+		return
+	}
+
+	recordAudit(c, h.auditService, services.AuditEntry{
+		UserID:     userID,
+		ActorID:    userID,
+		Action:     services.AuditSessionRevoked,
+		TargetType: "session",
+		TargetID:   sessionID,
+	})
+
+	// Ending the current session also drops its refresh cookie
+	if sessionID == c.GetString("session_id") {
+		secure := h.config.Server.Environment == "production"
+		utils.ClearRefreshTokenCookie(c, secure, h.config.Server.Domain)
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AuthHandler) logoutAll(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	revoked, err := h.authService.RevokeAllSessions(userID)
+	if err != nil {
+		utils.HandleError(c, utils.LogError("Failed to log out of all sessions", err))
+		return
+	}
+
+	recordAudit(c, h.auditService, services.AuditEntry{UserID: userID, ActorID: userID, Action: services.AuditAllSessionsRevoked})
+
+	secure := h.config.Server.Environment == "production"
+	utils.ClearRefreshTokenCookie(c, secure, h.config.Server.Domain)
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":           "success",
+		"message":          "Logged out of all sessions",
+		"revoked_sessions": revoked,
+	})
+}
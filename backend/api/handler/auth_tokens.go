@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"bytecast/api/utils"
+	apperrors "bytecast/internal/errors"
+	"bytecast/internal/models"
+	"bytecast/internal/services"
+)
+
+type createTokenRequest struct {
+	Name          string `json:"name" binding:"required,max=100"`
+	Scope         string `json:"scope" binding:"required,oneof=read write"`
+	ExpiresInDays int    `json:"expires_in_days" binding:"omitempty,min=1,max=365"`
+}
+
+type personalTokenResponse struct {
+	ID         uint              `json:"id"`
+	Name       string            `json:"name"`
+	Prefix     string            `json:"prefix"`
+	Scope      models.TokenScope `json:"scope"`
+	ExpiresAt  *time.Time        `json:"expires_at"`
+	LastUsedAt *time.Time        `json:"last_used_at"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+func personalTokenToResponse(pat *models.PersonalAccessToken) personalTokenResponse {
+	return personalTokenResponse{
+		ID:         pat.ID,
+		Name:       pat.Name,
+		Prefix:     pat.Prefix,
+		Scope:      pat.Scope,
+		ExpiresAt:  pat.ExpiresAt,
+		LastUsedAt: pat.LastUsedAt,
+		CreatedAt:  pat.CreatedAt,
+	}
+}
+
+func (h *AuthHandler) getPersonalTokens(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.authService.GetPersonalTokens(userID)
+	if err != nil {
+		utils.HandleError(c, utils.LogError("Failed to retrieve access tokens", err))
+		return
+	}
+
+	response := make([]personalTokenResponse, len(tokens))
+	for i := range tokens {
+		response[i] = personalTokenToResponse(&tokens[i])
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) createPersonalToken(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req createTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err, "Invalid input data")
+		return
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresInDays > 0 {
+		t := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		expiresAt = &t
+	}
+
+	pat, raw, err := h.authService.CreatePersonalToken(userID, req.Name, models.TokenScope(req.Scope), expiresAt)
+	if err != nil {
+		var appErr apperrors.AppError
+
+		switch err {
+		case services.ErrInvalidTokenScope:
+			appErr = apperrors.NewBadRequest("Invalid token scope", err)
+		case services.ErrPersonalTokenLimit:
+			appErr = apperrors.NewConflict("Access token limit reached, revoke an unused token first", err)
+		default:
+			appErr = utils.LogError("Failed to create access token", err)
+		}
+
+		utils.HandleError(c, appErr)
+		return
+	}
+
+	recordAudit(c, h.auditService, services.AuditEntry{
+		UserID:     userID,
+		ActorID:    userID,
+		Action:     services.AuditPersonalTokenCreated,
+		TargetType: "personal_access_token",
+		TargetID:   strconv.FormatUint(uint64(pat.ID), 10),
+		Detail:     string(pat.Scope) + " " + pat.Prefix,
+	})
+
+	// The plaintext token is shown once and never stored
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, gin.H{
+		"token":        raw,
+		"access_token": personalTokenToResponse(pat),
+	})
+}
+
+func (h *AuthHandler) revokePersonalToken(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	tokenID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.HandleError(c, apperrors.NewBadRequest("Invalid token ID", err))
+		return
+	}
+
+	if err := h.authService.RevokePersonalToken(userID, uint(tokenID)); err != nil {
+		var appErr apperrors.AppError
+
+		switch err {
+		case services.ErrPersonalTokenNotFound:
+			appErr = apperrors.NewNotFound("Access token not found", err)
+		default:
+			appErr = utils.LogError("Failed to revoke access token", err)
+		}
+
+		utils.HandleError(c, appErr)
+		return
+	}
+
+	recordAudit(c, h.auditService, services.AuditEntry{
+		UserID:     userID,
+		ActorID:    userID,
+		Action:     services.AuditPersonalTokenRevoked,
+		TargetType: "personal_access_token",
+		TargetID:   c.Param("id"),
+	})
+
+	c.Status(http.StatusNoContent)
+}
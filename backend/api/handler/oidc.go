@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"bytecast/api/middleware"
+	"bytecast/api/utils"
+	"bytecast/configs"
+	"bytecast/internal/oidc"
+	"bytecast/internal/services"
+)
+
+const (
+	oidcFlowCookie  = "oidc_flow"
+	oidcFlowMaxAge  = 10 * 60 // seconds the user has to finish signing in at the provider
+	oidcRoutePrefix = "/api/v1/auth/oidc"
+)
+
+/*
+ * OIDCHandler runs the browser side of signing in with an OpenID Connect
+ * provider. The login route stores the state, nonce and PKCE verifier in a
+ * short-lived cookie and redirects to the provider; the callback checks the
+ * state, redeems the code and starts a session like a password login would.
+ *
+ * The browser is then sent to the frontend callback page, which picks up the
+ * access token through the refresh cookie. Errors and two-factor challenges
+ * are passed along in the URL fragment so they stay out of server logs.
+ */
+type OIDCHandler struct {
+	authService  *services.AuthService
+	auditService *services.AuditService
+	provider     *oidc.Provider
+	frontendURL  string
+	config       *configs.Config
+}
+
+func NewOIDCHandler(authService *services.AuthService, auditService *services.AuditService, provider *oidc.Provider, frontendURL string, config *configs.Config) *OIDCHandler {
+	return &OIDCHandler{
+		authService:  authService,
+		auditService: auditService,
+		provider:     provider,
+		frontendURL:  frontendURL,
+		config:       config,
+	}
+}
+
+func (h *OIDCHandler) RegisterRoutes(r *gin.Engine, limiter *middleware.RateLimiter) {
+	group := r.Group(oidcRoutePrefix + "/" + h.provider.Name())
+	group.Use(limiter.Limit(middleware.RateLimitAuth))
+	{
+		group.GET("/login", h.login)
+		group.GET("/callback", h.callback)
+	}
+}
+
+func (h *OIDCHandler) login(c *gin.Context) {
+	flow, err := oidc.NewAuthFlow()
+	if err != nil {
+		utils.HandleError(c, utils.LogError("Failed to start sign-in", err))
+		return
+	}
+
+	h.setFlowCookie(c, strings.Join([]string{flow.State, flow.Nonce, flow.Verifier}, "."), oidcFlowMaxAge)
+	c.Redirect(http.StatusFound, h.provider.AuthCodeURL(flow))
+}
+
+func (h *OIDCHandler) callback(c *gin.Context) {
+	cookie, _ := c.Cookie(oidcFlowCookie)
+	h.setFlowCookie(c, "", -1)
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		h.redirect(c, url.Values{"error": {"access_denied"}})
+		return
+	}
+
+	parts := strings.Split(cookie, ".")
+	state := c.Query("state")
+	if len(parts) != 3 || state == "" || subtle.ConstantTimeCompare([]byte(parts[0]), []byte(state)) != 1 {
+		h.redirect(c, url.Values{"error": {"invalid_state"}})
+		return
+	}
+	flow := &oidc.AuthFlow{State: parts[0], Nonce: parts[1], Verifier: parts[2]}
+
+	identity, err := h.provider.Exchange(c.Request.Context(), c.Query("code"), flow)
+	if err != nil {
+		log.Printf("Warning: %s sign-in failed: %v", h.provider.Name(), err)
+		h.redirect(c, url.Values{"error": {"provider_error"}})
+		return
+	}
+
+	tokens, exp, err := h.authService.LoginWithIdentity(services.ExternalIdentity{
+		Provider:      h.provider.Name(),
+		Subject:       identity.Subject,
+		Email:         identity.Email,
+		EmailVerified: identity.EmailVerified,
+	}, clientInfo(c))
+	if err != nil {
+		var challenge *services.TwoFactorRequiredError
+
+		switch {
+		case errors.As(err, &challenge):
+			h.redirect(c, url.Values{"challenge_token": {challenge.ChallengeToken}})
+		case errors.Is(err, services.ErrIdentityEmailUnverified):
+			h.redirect(c, url.Values{"error": {"email_unverified"}})
+		case errors.Is(err, services.ErrAccountDisabled):
+			h.redirect(c, url.Values{"error": {"account_disabled"}})
+		case errors.Is(err, services.ErrRegistrationClosed):
+			h.redirect(c, url.Values{"error": {"registration_closed"}})
+		default:
+			log.Printf("Error: %s sign-in failed: %v", h.provider.Name(), err)
+			h.redirect(c, url.Values{"error": {"server_error"}})
+		}
+		return
+	}
+
+	if userID, ok := h.authService.RefreshTokenUserID(tokens.RefreshToken); ok {
+		recordAudit(c, h.auditService, services.AuditEntry{
+			UserID:  userID,
+			ActorID: userID,
+			Action:  services.AuditLogin,
+			Detail:  h.provider.Name(),
+		})
+	}
+
+	secure := h.config.Server.Environment == "production"
+	utils.SetRefreshTokenCookie(c, tokens.RefreshToken, exp, secure, h.config.Server.Domain)
+	h.redirect(c, nil)
+}
+
+// setFlowCookie must be Lax, not Strict, to come back on the provider's redirect
+func (h *OIDCHandler) setFlowCookie(c *gin.Context, value string, maxAge int) {
+	utils.SetCookie(c, utils.CookieOptions{
+		Name:     oidcFlowCookie,
+		Value:    value,
+		MaxAge:   maxAge,
+		Path:     oidcRoutePrefix,
+		Domain:   h.config.Server.Domain,
+		Secure:   h.config.Server.Environment == "production",
+		HTTPOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (h *OIDCHandler) redirect(c *gin.Context, fragment url.Values) {
+	target := h.frontendURL
+	if len(fragment) > 0 {
+		target += "#" + fragment.Encode()
+	}
+	c.Redirect(http.StatusFound, target)
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"bytecast/api/middleware"
+	"bytecast/api/utils"
+	apperrors "bytecast/internal/errors"
+	"bytecast/internal/models"
+	"bytecast/internal/services"
+)
+
+// updatePreferencesRequest is a partial update: fields left out keep their value
+type updatePreferencesRequest struct {
+	Timezone           *string                     `json:"timezone" binding:"omitnil,timezone"`
+	DefaultWatchlistID *uint                       `json:"default_watchlist_id"` // Zero clears the default
+	FeedSort           *string                     `json:"feed_sort" binding:"omitnil,oneof=newest oldest"`
+	HideShorts         *bool                       `json:"hide_shorts"`
+	Theme              *string                     `json:"theme" binding:"omitnil,oneof=system light dark"`
+	Locale             *string                     `json:"locale" binding:"omitnil,max=35,bcp47_language_tag"`
+	Notifications      *notificationSettingsUpdate `json:"notifications"`
+}
+
+type notificationSettingsUpdate struct {
+	NewVideos            *bool `json:"new_videos"`
+	WatchlistInvitations *bool `json:"watchlist_invitations"`
+}
+
+func (r *updatePreferencesRequest) toUpdate() services.PreferencesUpdate {
+	update := services.PreferencesUpdate{
+		Timezone:           r.Timezone,
+		DefaultWatchlistID: r.DefaultWatchlistID,
+		HideShorts:         r.HideShorts,
+		Locale:             r.Locale,
+	}
+	if r.FeedSort != nil {
+		sort := models.FeedSort(*r.FeedSort)
+		update.FeedSort = &sort
+	}
+	if r.Theme != nil {
+		theme := models.Theme(*r.Theme)
+		update.Theme = &theme
+	}
+	if r.Notifications != nil {
+		update.NotifyNewVideos = r.Notifications.NewVideos
+		update.NotifyWatchlistInvitations = r.Notifications.WatchlistInvitations
+	}
+	return update
+}
+
+type PreferencesHandler struct {
+	preferencesService *services.PreferencesService
+}
+
+func NewPreferencesHandler(preferencesService *services.PreferencesService) *PreferencesHandler {
+	return &PreferencesHandler{
+		preferencesService: preferencesService,
+	}
+}
+
+func (h *PreferencesHandler) RegisterRoutes(r *gin.Engine, authMiddleware gin.HandlerFunc, limiter *middleware.RateLimiter) {
+	users := r.Group("/api/v1/users")
+	users.Use(authMiddleware, limiter.Limit(middleware.RateLimitAPI))
+
+	users.GET("/me/preferences", h.getPreferences)
+	users.PATCH("/me/preferences", h.updatePreferences)
+}
+
+func (h *PreferencesHandler) getPreferences(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	prefs, err := h.preferencesService.GetPreferences(userID)
+	if err != nil {
+		utils.HandleError(c, utils.LogError("Failed to retrieve preferences", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+func (h *PreferencesHandler) updatePreferences(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req updatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err, "Invalid preferences")
+		return
+	}
+
+	prefs, err := h.preferencesService.UpdatePreferences(userID, req.toUpdate())
+	if err != nil {
+		var appErr apperrors.AppError
+
+		switch err {
+		case services.ErrWatchlistNotFound:
+			appErr = apperrors.NewBadRequest("Default watchlist not found", err)
+		default:
+			appErr = utils.LogError("Failed to update preferences", err)
+		}
+
+		utils.HandleError(c, appErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+func (h *PreferencesHandler) getUserID(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.HandleError(c, apperrors.NewUnauthorized("Not authenticated", nil))
+		return 0, false
+	}
+
+	id, ok := userID.(uint)
+	if !ok {
+		utils.HandleError(c, utils.LogError("Invalid user ID format", nil))
+		return 0, false
+	}
+
+	return id, true
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 
+	"bytecast/api/middleware"
 	"bytecast/api/utils"
 	apperrors "bytecast/internal/errors"
 	"bytecast/internal/models"
@@ -33,13 +34,31 @@ type addChannelRequest struct {
 	ChannelID string `json:"channel_id" binding:"required"` // Can be URL or ID
 }
 
+type duplicateWatchlistRequest struct {
+	Name string `json:"name" binding:"max=255"` // Defaults to "<name> (copy)"
+}
+
+type targetWatchlistRequest struct {
+	TargetID uint `json:"target_id" binding:"required"`
+}
+
 type watchlistResponse struct {
 	ID          uint   `json:"id"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Color       string `json:"color"`
+	Role        string `json:"role,omitempty"`
+	ShareSlug   string `json:"share_slug,omitempty"`
 	CreatedAt   string `json:"created_at"`
 	UpdatedAt   string `json:"updated_at"`
+	DeletedAt   string `json:"deleted_at,omitempty"`
+}
+
+type sharedWatchlistResponse struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Color       string            `json:"color"`
+	Channels    []channelResponse `json:"channels"`
 }
 
 func init() {
@@ -63,27 +82,56 @@ type channelResponse struct {
 
 type WatchlistHandler struct {
 	watchlistService *services.WatchlistService
+	auditService     *services.AuditService
 }
 
-func NewWatchlistHandler(watchlistService *services.WatchlistService) *WatchlistHandler {
+func NewWatchlistHandler(watchlistService *services.WatchlistService, auditService *services.AuditService) *WatchlistHandler {
 	return &WatchlistHandler{
 		watchlistService: watchlistService,
+		auditService:     auditService,
 	}
 }
 
-func (h *WatchlistHandler) RegisterRoutes(r *gin.Engine, authMiddleware gin.HandlerFunc) {
+func (h *WatchlistHandler) RegisterRoutes(r *gin.Engine, authMiddleware gin.HandlerFunc, limiter *middleware.RateLimiter) {
 	watchlists := r.Group("/api/v1/watchlists")
 	watchlists.Use(authMiddleware) // Require authentication for all watchlist routes
+	watchlists.Use(limiter.Limit(middleware.RateLimitAPI))
 
 	watchlists.POST("", h.createWatchlist)
 	watchlists.GET("", h.getUserWatchlists)
+	watchlists.GET("/trash", h.getTrashedWatchlists)
 	watchlists.GET("/:id", h.getWatchlist)
 	watchlists.PUT("/:id", h.updateWatchlist)
 	watchlists.DELETE("/:id", h.deleteWatchlist)
+	watchlists.POST("/:id/restore", h.restoreWatchlist)
 
-	watchlists.POST("/:id/channels", h.addChannel)
+	watchlists.POST("/:id/channels", limiter.Limit(middleware.RateLimitChannels), h.addChannel)
 	watchlists.GET("/:id/channels", h.getChannels)
 	watchlists.DELETE("/:id/channels/:channel_id", h.removeChannel)
+	watchlists.POST("/:id/channels/:channel_id/move", h.moveChannel)
+
+	watchlists.POST("/:id/duplicate", h.duplicateWatchlist)
+	watchlists.POST("/:id/merge", h.mergeWatchlist)
+
+	watchlists.POST("/:id/share", h.createShareLink)
+	watchlists.DELETE("/:id/share", h.revokeShareLink)
+
+	watchlists.GET("/:id/members", h.getMembers)
+	watchlists.POST("/:id/invitations", h.inviteMember)
+	watchlists.PUT("/:id/members/:user_id", h.updateMemberRole)
+	watchlists.DELETE("/:id/members/:user_id", h.removeMember)
+
+	invitations := r.Group("/api/v1/invitations")
+	invitations.Use(authMiddleware, limiter.Limit(middleware.RateLimitAPI))
+	invitations.GET("", h.getInvitations)
+	invitations.POST("/:id/accept", h.acceptInvitation)
+	invitations.POST("/:id/decline", h.declineInvitation)
+
+	// Shared watchlists are readable without authentication
+	public := r.Group("/api/v1/public/watchlists")
+	public.Use(limiter.Limit(middleware.RateLimitAPI))
+	public.GET("/:slug", h.getSharedWatchlist)
+	public.POST("/:slug/clone", authMiddleware, h.cloneSharedWatchlist)
 }
 
 func (h *WatchlistHandler) getUserID(c *gin.Context) (uint, bool) {
@@ -114,6 +162,8 @@ func (h *WatchlistHandler) createWatchlist(c *gin.Context) {
 		return
 	}
 
+	h.recordWatchlistEvent(c, userID, services.AuditWatchlistCreated, watchlist.ID, watchlist.Name)
+
 	c.JSON(http.StatusCreated, watchlistToResponse(watchlist))
 }
 
@@ -188,12 +238,16 @@ func (h *WatchlistHandler) updateWatchlist(c *gin.Context) {
 		switch err {
 		case services.ErrWatchlistNotFound:
 			utils.HandleError(c, apperrors.NewNotFound("Watchlist not found", err))
+		case services.ErrNotAuthorized:
+			utils.HandleError(c, apperrors.NewForbidden("You do not have permission to modify this watchlist", err))
 		default:
 			utils.HandleError(c, apperrors.NewInternal("Failed to update watchlist", err))
 		}
 		return
 	}
 
+	h.recordWatchlistEvent(c, userID, services.AuditWatchlistUpdated, watchlist.ID, watchlist.Name)
+
 	c.JSON(http.StatusOK, watchlistToResponse(watchlist))
 }
 
@@ -214,15 +268,67 @@ func (h *WatchlistHandler) deleteWatchlist(c *gin.Context) {
 		switch err {
 		case services.ErrWatchlistNotFound:
 			utils.HandleError(c, apperrors.NewNotFound("Watchlist not found", err))
+		case services.ErrNotAuthorized:
+			utils.HandleError(c, apperrors.NewForbidden("You do not have permission to modify this watchlist", err))
 		default:
 			utils.HandleError(c, apperrors.NewInternal("Failed to delete watchlist", err))
 		}
 		return
 	}
 
+	h.recordWatchlistEvent(c, userID, services.AuditWatchlistDeleted, uint(watchlistID), "")
+
 	c.Status(http.StatusNoContent)
 }
 
+func (h *WatchlistHandler) getTrashedWatchlists(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	watchlists, err := h.watchlistService.GetTrashedWatchlists(userID)
+	if err != nil {
+		utils.HandleError(c, apperrors.NewInternal("Failed to retrieve trashed watchlists", err))
+		return
+	}
+
+	response := make([]watchlistResponse, len(watchlists))
+	for i, watchlist := range watchlists {
+		response[i] = watchlistToResponse(&watchlist)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"watchlists": response,
+	})
+}
+
+func (h *WatchlistHandler) restoreWatchlist(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	watchlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.HandleError(c, apperrors.NewBadRequest("Invalid watchlist ID", err))
+		return
+	}
+
+	watchlist, err := h.watchlistService.RestoreWatchlist(uint(watchlistID), userID)
+	if err != nil {
+		switch err {
+		case services.ErrWatchlistNotFound:
+			utils.HandleError(c, apperrors.NewNotFound("Watchlist not found in trash", err))
+		default:
+			utils.HandleError(c, apperrors.NewInternal("Failed to restore watchlist", err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, watchlistToResponse(watchlist))
+}
+
 func (h *WatchlistHandler) addChannel(c *gin.Context) {
 	userID, ok := h.getUserID(c)
 	if !ok {
@@ -246,6 +352,8 @@ func (h *WatchlistHandler) addChannel(c *gin.Context) {
 		switch err {
 		case services.ErrWatchlistNotFound:
 			utils.HandleError(c, apperrors.NewNotFound("Watchlist not found", err))
+		case services.ErrNotAuthorized:
+			utils.HandleError(c, apperrors.NewForbidden("You do not have permission to modify this watchlist", err))
 		case services.ErrInvalidYouTubeID:
 			utils.HandleError(c, apperrors.NewBadRequest("Invalid YouTube channel ID or URL", err))
 		case services.ErrYouTubeAPIError:
@@ -258,6 +366,8 @@ func (h *WatchlistHandler) addChannel(c *gin.Context) {
 		return
 	}
 
+	h.recordWatchlistEvent(c, userID, services.AuditChannelAdded, uint(watchlistID), req.ChannelID)
+
 	c.Status(http.StatusOK)
 }
 
@@ -317,6 +427,8 @@ func (h *WatchlistHandler) removeChannel(c *gin.Context) {
 		switch err {
 		case services.ErrWatchlistNotFound:
 			utils.HandleError(c, apperrors.NewNotFound("Watchlist not found", err))
+		case services.ErrNotAuthorized:
+			utils.HandleError(c, apperrors.NewForbidden("You do not have permission to modify this watchlist", err))
 		case services.ErrChannelNotFound:
 			utils.HandleError(c, apperrors.NewNotFound("Channel not found in watchlist", err))
 		default:
@@ -325,18 +437,240 @@ func (h *WatchlistHandler) removeChannel(c *gin.Context) {
 		return
 	}
 
+	h.recordWatchlistEvent(c, userID, services.AuditChannelRemoved, uint(watchlistID), channelID)
+
 	c.Status(http.StatusNoContent)
 }
 
+func (h *WatchlistHandler) duplicateWatchlist(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	watchlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.HandleError(c, apperrors.NewBadRequest("Invalid watchlist ID", err))
+		return
+	}
+
+	var req duplicateWatchlistRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.HandleValidationError(c, err, "Invalid request format")
+			return
+		}
+	}
+
+	watchlist, err := h.watchlistService.DuplicateWatchlist(uint(watchlistID), userID, req.Name)
+	if err != nil {
+		switch err {
+		case services.ErrWatchlistNotFound:
+			utils.HandleError(c, apperrors.NewNotFound("Watchlist not found", err))
+		default:
+			utils.HandleError(c, apperrors.NewInternal("Failed to duplicate watchlist", err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, watchlistToResponse(watchlist))
+}
+
+func (h *WatchlistHandler) mergeWatchlist(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	watchlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.HandleError(c, apperrors.NewBadRequest("Invalid watchlist ID", err))
+		return
+	}
+
+	var req targetWatchlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err, "Invalid request format")
+		return
+	}
+
+	watchlist, err := h.watchlistService.MergeWatchlists(uint(watchlistID), req.TargetID, userID)
+	if err != nil {
+		switch err {
+		case services.ErrWatchlistNotFound:
+			utils.HandleError(c, apperrors.NewNotFound("Watchlist not found", err))
+		case services.ErrNotAuthorized:
+			utils.HandleError(c, apperrors.NewForbidden("You do not have permission to modify this watchlist", err))
+		case services.ErrSameWatchlist:
+			utils.HandleError(c, apperrors.NewBadRequest("Cannot merge a watchlist into itself", err))
+		default:
+			utils.HandleError(c, apperrors.NewInternal("Failed to merge watchlists", err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, watchlistToResponse(watchlist))
+}
+
+func (h *WatchlistHandler) moveChannel(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	watchlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.HandleError(c, apperrors.NewBadRequest("Invalid watchlist ID", err))
+		return
+	}
+
+	var req targetWatchlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err, "Invalid request format")
+		return
+	}
+
+	err = h.watchlistService.MoveChannel(uint(watchlistID), req.TargetID, userID, c.Param("channel_id"))
+	if err != nil {
+		switch err {
+		case services.ErrWatchlistNotFound:
+			utils.HandleError(c, apperrors.NewNotFound("Watchlist not found", err))
+		case services.ErrNotAuthorized:
+			utils.HandleError(c, apperrors.NewForbidden("You do not have permission to modify this watchlist", err))
+		case services.ErrChannelNotFound:
+			utils.HandleError(c, apperrors.NewNotFound("Channel not found in watchlist", err))
+		case services.ErrSameWatchlist:
+			utils.HandleError(c, apperrors.NewBadRequest("Source and target watchlist must be different", err))
+		default:
+			utils.HandleError(c, apperrors.NewInternal("Failed to move channel", err))
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WatchlistHandler) createShareLink(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	watchlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.HandleError(c, apperrors.NewBadRequest("Invalid watchlist ID", err))
+		return
+	}
+
+	watchlist, err := h.watchlistService.CreateShareLink(uint(watchlistID), userID)
+	if err != nil {
+		switch err {
+		case services.ErrWatchlistNotFound:
+			utils.HandleError(c, apperrors.NewNotFound("Watchlist not found", err))
+		case services.ErrNotAuthorized:
+			utils.HandleError(c, apperrors.NewForbidden("You do not have permission to modify this watchlist", err))
+		default:
+			utils.HandleError(c, apperrors.NewInternal("Failed to create share link", err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, watchlistToResponse(watchlist))
+}
+
+func (h *WatchlistHandler) revokeShareLink(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	watchlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.HandleError(c, apperrors.NewBadRequest("Invalid watchlist ID", err))
+		return
+	}
+
+	err = h.watchlistService.RevokeShareLink(uint(watchlistID), userID)
+	if err != nil {
+		switch err {
+		case services.ErrWatchlistNotFound:
+			utils.HandleError(c, apperrors.NewNotFound("Watchlist not found", err))
+		case services.ErrNotAuthorized:
+			utils.HandleError(c, apperrors.NewForbidden("You do not have permission to modify this watchlist", err))
+		default:
+			utils.HandleError(c, apperrors.NewInternal("Failed to revoke share link", err))
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WatchlistHandler) getSharedWatchlist(c *gin.Context) {
+	watchlist, err := h.watchlistService.GetSharedWatchlist(c.Param("slug"))
+	if err != nil {
+		switch err {
+		case services.ErrShareLinkNotFound:
+			utils.HandleError(c, apperrors.NewNotFound("Shared watchlist not found", err))
+		default:
+			utils.HandleError(c, apperrors.NewInternal("Failed to retrieve shared watchlist", err))
+		}
+		return
+	}
+
+	channels := make([]channelResponse, len(watchlist.Channels))
+	for i, channel := range watchlist.Channels {
+		channels[i] = channelToResponse(channel)
+	}
+
+	c.JSON(http.StatusOK, sharedWatchlistResponse{
+		Name:        watchlist.Name,
+		Description: watchlist.Description,
+		Color:       watchlist.Color,
+		Channels:    channels,
+	})
+}
+
+func (h *WatchlistHandler) cloneSharedWatchlist(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	watchlist, err := h.watchlistService.CloneSharedWatchlist(c.Param("slug"), userID)
+	if err != nil {
+		switch err {
+		case services.ErrShareLinkNotFound:
+			utils.HandleError(c, apperrors.NewNotFound("Shared watchlist not found", err))
+		default:
+			utils.HandleError(c, apperrors.NewInternal("Failed to clone shared watchlist", err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, watchlistToResponse(watchlist))
+}
+
 func watchlistToResponse(watchlist *models.Watchlist) watchlistResponse {
-	return watchlistResponse{
+	response := watchlistResponse{
 		ID:          watchlist.ID,
 		Name:        watchlist.Name,
 		Description: watchlist.Description,
 		Color:       watchlist.Color,
+		Role:        string(watchlist.Role),
 		CreatedAt:   watchlist.CreatedAt.Format("2006-01-02T15:04:05Z"),
 		UpdatedAt:   watchlist.UpdatedAt.Format("2006-01-02T15:04:05Z"),
 	}
+
+	if watchlist.ShareSlug != nil {
+		response.ShareSlug = *watchlist.ShareSlug
+	}
+
+	if watchlist.DeletedAt.Valid {
+		response.DeletedAt = watchlist.DeletedAt.Time.Format("2006-01-02T15:04:05Z")
+	}
+
+	return response
 }
 
 func channelToResponse(channel *models.Channel) channelResponse {
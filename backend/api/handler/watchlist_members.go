@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"bytecast/api/utils"
+	apperrors "bytecast/internal/errors"
+	"bytecast/internal/models"
+	"bytecast/internal/services"
+)
+
+type inviteMemberRequest struct {
+	Identifier string `json:"identifier" binding:"required,min=3"` // Username or email
+	Role       string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+type updateMemberRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+type memberResponse struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+type invitationResponse struct {
+	ID            uint   `json:"id"`
+	WatchlistID   uint   `json:"watchlist_id"`
+	WatchlistName string `json:"watchlist_name,omitempty"`
+	InvitedBy     string `json:"invited_by,omitempty"`
+	Invitee       string `json:"invitee,omitempty"`
+	Role          string `json:"role"`
+	Status        string `json:"status"`
+	CreatedAt     string `json:"created_at"`
+}
+
+func (h *WatchlistHandler) getMembers(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	watchlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.HandleError(c, apperrors.NewBadRequest("Invalid watchlist ID", err))
+		return
+	}
+
+	members, err := h.watchlistService.GetMembers(uint(watchlistID), userID)
+	if err != nil {
+		switch err {
+		case services.ErrWatchlistNotFound:
+			utils.HandleError(c, apperrors.NewNotFound("Watchlist not found", err))
+		default:
+			utils.HandleError(c, apperrors.NewInternal("Failed to retrieve members", err))
+		}
+		return
+	}
+
+	response := make([]memberResponse, len(members))
+	for i, member := range members {
+		response[i] = memberResponse{
+			UserID:   member.UserID,
+			Username: member.User.Username,
+			Role:     string(member.Role),
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"members": response,
+	})
+}
+
+func (h *WatchlistHandler) inviteMember(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	watchlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.HandleError(c, apperrors.NewBadRequest("Invalid watchlist ID", err))
+		return
+	}
+
+	var req inviteMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err, "Invalid request format")
+		return
+	}
+
+	invitation, err := h.watchlistService.InviteMember(uint(watchlistID), userID, req.Identifier, models.WatchlistRole(req.Role))
+	if err != nil {
+		switch err {
+		case services.ErrWatchlistNotFound:
+			utils.HandleError(c, apperrors.NewNotFound("Watchlist not found", err))
+		case services.ErrNotAuthorized:
+			utils.HandleError(c, apperrors.NewForbidden("Only the owner can invite members", err))
+		case services.ErrInviteeNotFound:
+			utils.HandleError(c, apperrors.NewNotFound("No user found with that username or email", err))
+		case services.ErrInvalidInvitation, services.ErrInvalidWatchlistRole:
+			utils.HandleError(c, apperrors.NewBadRequest("Cannot invite this user to the watchlist", err))
+		case services.ErrAlreadyMember:
+			utils.HandleError(c, apperrors.NewConflict("This user is already a member of the watchlist", err))
+		default:
+			utils.HandleError(c, apperrors.NewInternal("Failed to invite member", err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitationToResponse(invitation))
+}
+
+func (h *WatchlistHandler) updateMemberRole(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	watchlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.HandleError(c, apperrors.NewBadRequest("Invalid watchlist ID", err))
+		return
+	}
+
+	memberID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		utils.HandleError(c, apperrors.NewBadRequest("Invalid user ID", err))
+		return
+	}
+
+	var req updateMemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleValidationError(c, err, "Invalid request format")
+		return
+	}
+
+	err = h.watchlistService.UpdateMemberRole(uint(watchlistID), userID, uint(memberID), models.WatchlistRole(req.Role))
+	if err != nil {
+		switch err {
+		case services.ErrWatchlistNotFound:
+			utils.HandleError(c, apperrors.NewNotFound("Watchlist not found", err))
+		case services.ErrNotAuthorized:
+			utils.HandleError(c, apperrors.NewForbidden("Only the owner can change member roles", err))
+		case services.ErrMemberNotFound:
+			utils.HandleError(c, apperrors.NewNotFound("Member not found", err))
+		case services.ErrInvalidWatchlistRole:
+			utils.HandleError(c, apperrors.NewBadRequest("Invalid role", err))
+		default:
+			utils.HandleError(c, apperrors.NewInternal("Failed to update member role", err))
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WatchlistHandler) removeMember(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	watchlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.HandleError(c, apperrors.NewBadRequest("Invalid watchlist ID", err))
+		return
+	}
+
+	memberID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		utils.HandleError(c, apperrors.NewBadRequest("Invalid user ID", err))
+		return
+	}
+
+	err = h.watchlistService.RemoveMember(uint(watchlistID), userID, uint(memberID))
+	if err != nil {
+		switch err {
+		case services.ErrWatchlistNotFound:
+			utils.HandleError(c, apperrors.NewNotFound("Watchlist not found", err))
+		case services.ErrNotAuthorized:
+			utils.HandleError(c, apperrors.NewForbidden("Only the owner can remove other members", err))
+		case services.ErrMemberNotFound:
+			utils.HandleError(c, apperrors.NewNotFound("Member not found", err))
+		default:
+			utils.HandleError(c, apperrors.NewInternal("Failed to remove member", err))
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WatchlistHandler) getInvitations(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	invitations, err := h.watchlistService.GetPendingInvitations(userID)
+	if err != nil {
+		utils.HandleError(c, apperrors.NewInternal("Failed to retrieve invitations", err))
+		return
+	}
+
+	response := make([]invitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		response[i] = invitationToResponse(&invitation)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"invitations": response,
+	})
+}
+
+func (h *WatchlistHandler) acceptInvitation(c *gin.Context) {
+	h.respondToInvitation(c, true)
+}
+
+func (h *WatchlistHandler) declineInvitation(c *gin.Context) {
+	h.respondToInvitation(c, false)
+}
+
+func (h *WatchlistHandler) respondToInvitation(c *gin.Context, accept bool) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	invitationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.HandleError(c, apperrors.NewBadRequest("Invalid invitation ID", err))
+		return
+	}
+
+	if err := h.watchlistService.RespondToInvitation(uint(invitationID), userID, accept); err != nil {
+		switch err {
+		case services.ErrInvitationNotFound:
+			utils.HandleError(c, apperrors.NewNotFound("Invitation not found", err))
+		default:
+			utils.HandleError(c, apperrors.NewInternal("Failed to respond to invitation", err))
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func invitationToResponse(invitation *models.WatchlistInvitation) invitationResponse {
+	return invitationResponse{
+		ID:            invitation.ID,
+		WatchlistID:   invitation.WatchlistID,
+		WatchlistName: invitation.Watchlist.Name,
+		InvitedBy:     invitation.Inviter.Username,
+		Invitee:       invitation.Invitee.Username,
+		Role:          string(invitation.Role),
+		Status:        string(invitation.Status),
+		CreatedAt:     invitation.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
@@ -6,15 +6,38 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt"
+	"gorm.io/gorm"
+
+	"bytecast/internal/models"
+	"bytecast/internal/services"
+	"bytecast/internal/token"
 )
 
 var (
-	ErrMissingToken = errors.New("missing authorization token")
-	ErrInvalidToken = errors.New("invalid token")
+	ErrMissingToken    = errors.New("missing authorization token")
+	ErrInvalidToken    = errors.New("invalid token")
+	ErrTokenRevoked    = errors.New("token has been revoked")
+	ErrAccountDisabled = errors.New("account has been disabled")
 )
 
-func AuthMiddleware(jwtSecret []byte) gin.HandlerFunc {
+// TokenVersionLookup resolves a user's current token version. Access tokens
+// carrying an older version have been revoked.
+type TokenVersionLookup interface {
+	TokenVersion(userID uint) (uint, error)
+}
+
+// PersonalTokenLookup resolves a personal access token to its user and scope
+type PersonalTokenLookup interface {
+	AuthenticatePersonalToken(token string) (uint, string, error)
+}
+
+/*
+ * AuthMiddleware authenticates requests with an access token. When personal
+ * is non-nil, personal access tokens are accepted as well; read-scoped ones
+ * are limited to safe methods. Routes that manage the account itself are
+ * given a middleware without personal tokens.
+ */
+func AuthMiddleware(tokens *token.Manager, versions TokenVersionLookup, personal PersonalTokenLookup) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -31,44 +54,88 @@ func AuthMiddleware(jwtSecret []byte) gin.HandlerFunc {
 			return
 		}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, ErrInvalidToken
-			}
-			return jwtSecret, nil
-		})
+		if strings.HasPrefix(tokenString, services.PersonalTokenPrefix) {
+			authenticatePersonalToken(c, personal, tokenString)
+			return
+		}
 
+		claims, err := tokens.Parse(tokenString, token.TypeAccess)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			c.JSON(http.StatusUnauthorized, gin.H{"error": ErrInvalidToken.Error()})
 			c.Abort()
 			return
 		}
 
-		if !token.Valid {
+		userID, err := claims.UserID()
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": ErrInvalidToken.Error()})
 			c.Abort()
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": ErrInvalidToken.Error()})
+		// Reject tokens issued before the user's last logout-all or password change
+		currentVersion, err := versions.TokenVersion(userID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": ErrInvalidToken.Error()})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify token"})
+			}
 			c.Abort()
 			return
 		}
-
-		// Verify token type is "access"
-		if tokenType, ok := claims["type"].(string); !ok || tokenType != "access" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token type"})
+		if claims.TokenVersion != currentVersion {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": ErrTokenRevoked.Error()})
 			c.Abort()
 			return
 		}
 
 		// Add user ID to context
-		if userID, ok := claims["user_id"].(float64); ok {
-			c.Set("user_id", uint(userID))
+		c.Set("user_id", userID)
+
+		// Add the session the token was issued for, if any
+		if claims.FamilyID != "" {
+			c.Set("session_id", claims.FamilyID)
 		}
 
 		c.Next()
 	}
 }
+
+func authenticatePersonalToken(c *gin.Context, personal PersonalTokenLookup, tokenString string) {
+	if personal == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "personal access tokens are not accepted for this endpoint"})
+		c.Abort()
+		return
+	}
+
+	userID, scope, err := personal.AuthenticatePersonalToken(tokenString)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTokenRevoked):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": ErrTokenRevoked.Error()})
+		case errors.Is(err, services.ErrTokenInvalid):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": ErrInvalidToken.Error()})
+		case errors.Is(err, services.ErrAccountDisabled):
+			c.JSON(http.StatusForbidden, gin.H{"error": ErrAccountDisabled.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify token"})
+		}
+		c.Abort()
+		return
+	}
+
+	if scope != string(models.TokenScopeWrite) && !isSafeMethod(c.Request.Method) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "token scope does not allow this request"})
+		c.Abort()
+		return
+	}
+
+	c.Set("user_id", userID)
+	c.Set("token_scope", scope)
+	c.Next()
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
@@ -1,6 +1,9 @@
 package middleware
 
 import (
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -8,7 +11,7 @@ import (
 
 // CORSConfig contains the configuration for the CORS middleware
 type CORSConfig struct {
-	AllowOrigins     []string
+	AllowOrigins     []string // Exact origins, "https://*.example.com" subdomain patterns, or "*"
 	AllowMethods     []string
 	AllowHeaders     []string
 	ExposeHeaders    []string
@@ -16,13 +19,13 @@ type CORSConfig struct {
 	MaxAge           time.Duration
 }
 
-// DefaultCORSConfig returns a default CORS configuration
+// DefaultCORSConfig returns a default CORS configuration for local development
 func DefaultCORSConfig() *CORSConfig {
 	return &CORSConfig{
-		AllowOrigins:     []string{"*"},
+		AllowOrigins:     []string{"http://localhost:4200"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Content-Length", "Accept-Encoding", "Authorization", "X-CSRF-Token", "X-Request-ID"},
-		ExposeHeaders:    []string{"Content-Length", "X-Request-ID"},
+		ExposeHeaders:    []string{"Content-Length", "X-Request-ID", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}
@@ -34,65 +37,136 @@ func CORS() gin.HandlerFunc {
 	return CORSWithConfig(DefaultCORSConfig())
 }
 
-// CORSWithConfig returns a CORS middleware with custom configuration
+/*
+ * CORSWithConfig returns a CORS middleware with custom configuration. Allowed
+ * origins are echoed back; any other origin gets no CORS headers, and its
+ * preflight requests are refused so the browser never sends the real request.
+ */
 func CORSWithConfig(config *CORSConfig) gin.HandlerFunc {
+	origins := newOriginMatcher(config.AllowOrigins)
+	allowMethods := strings.Join(config.AllowMethods, ", ")
+	allowHeaders := strings.Join(config.AllowHeaders, ", ")
+	exposeHeaders := strings.Join(config.ExposeHeaders, ", ")
+	maxAge := strconv.Itoa(int(config.MaxAge.Seconds()))
+
+	// A wildcard origin cannot be combined with credentials, browsers reject it
+	wildcard := origins.any && !config.AllowCredentials
+
 	return func(c *gin.Context) {
-		// Set CORS headers
+		header := c.Writer.Header()
 		origin := c.GetHeader("Origin")
-		allowOrigin := "*"
-		
-		// If specific origins are defined and the request origin matches one of them,
-		// use that specific origin instead of the wildcard
-		if len(config.AllowOrigins) > 0 && config.AllowOrigins[0] != "*" {
-			allowOrigin = ""
-			for _, o := range config.AllowOrigins {
-				if o == origin {
-					allowOrigin = origin
-					break
-				}
-			}
-			if allowOrigin == "" {
-				allowOrigin = config.AllowOrigins[0]
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		// The response depends on the Origin header, so caches must key on it
+		header.Add("Vary", "Origin")
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		if !origins.allows(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
 			}
+			c.Next()
+			return
+		}
+
+		if wildcard {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
 		}
-		
-		c.Writer.Header().Set("Access-Control-Allow-Origin", allowOrigin)
-		
+
 		if config.AllowCredentials {
-			c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+			header.Set("Access-Control-Allow-Credentials", "true")
 		}
-		
-		if len(config.ExposeHeaders) > 0 {
-			c.Writer.Header().Set("Access-Control-Expose-Headers", 
-				joinStrings(config.ExposeHeaders, ", "))
+
+		if exposeHeaders != "" {
+			header.Set("Access-Control-Expose-Headers", exposeHeaders)
 		}
-		
-		// Handle preflight requests
-		if c.Request.Method == "OPTIONS" {
-			c.Writer.Header().Set("Access-Control-Allow-Methods", 
-				joinStrings(config.AllowMethods, ", "))
-			c.Writer.Header().Set("Access-Control-Allow-Headers", 
-				joinStrings(config.AllowHeaders, ", "))
-			c.Writer.Header().Set("Access-Control-Max-Age", 
-				time.Duration(config.MaxAge.Seconds()).String())
-			c.AbortWithStatus(204)
+
+		if preflight {
+			header.Set("Access-Control-Allow-Methods", allowMethods)
+			header.Set("Access-Control-Allow-Headers", allowHeaders)
+			header.Set("Access-Control-Max-Age", maxAge)
+			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
-		
+
 		c.Next()
 	}
 }
 
-// Helper function to join strings with separator
-func joinStrings(strings []string, separator string) string {
-	if len(strings) == 0 {
-		return ""
+// originPattern matches "scheme://*.domain[:port]" against any subdomain
+type originPattern struct {
+	prefix string // "https://"
+	suffix string // ".example.com" or ".example.com:8443"
+}
+
+type originMatcher struct {
+	any      bool
+	exact    map[string]bool
+	patterns []originPattern
+}
+
+func newOriginMatcher(allowed []string) *originMatcher {
+	m := &originMatcher{exact: make(map[string]bool)}
+	for _, origin := range allowed {
+		origin = strings.ToLower(strings.TrimRight(origin, "/"))
+		switch {
+		case origin == "*":
+			m.any = true
+		case strings.Contains(origin, "://*."):
+			i := strings.Index(origin, "*.")
+			m.patterns = append(m.patterns, originPattern{prefix: origin[:i], suffix: origin[i+1:]})
+		default:
+			m.exact[origin] = true
+		}
+	}
+	return m
+}
+
+func (m *originMatcher) allows(origin string) bool {
+	if m.any {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	if m.exact[origin] {
+		return true
+	}
+
+	for _, p := range m.patterns {
+		if !strings.HasPrefix(origin, p.prefix) || !strings.HasSuffix(origin, p.suffix) {
+			continue
+		}
+		subdomain := origin[len(p.prefix) : len(origin)-len(p.suffix)]
+		if subdomain != "" && isHostLabels(subdomain) {
+			return true
+		}
 	}
-	
-	result := strings[0]
-	for i := 1; i < len(strings); i++ {
-		result += separator + strings[i]
+	return false
+}
+
+// isHostLabels reports whether s is one or more dot-separated DNS labels, so a
+// pattern cannot be satisfied with a port, path or userinfo smuggled in
+func isHostLabels(s string) bool {
+	for _, label := range strings.Split(s, ".") {
+		if label == "" {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+				return false
+			}
+		}
 	}
-	
-	return result
-} 
\ No newline at end of file
+	return true
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"bytecast/internal/ratelimit"
+)
+
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// Route groups with their own rate limit
+const (
+	RateLimitAuth     = "auth"     // Sign-in and account endpoints
+	RateLimitAPI      = "api"      // Watchlist API
+	RateLimitChannels = "channels" // Adding channels, which spends YouTube API quota
+)
+
+// RateLimiter hands out rate limiting middleware for route groups
+type RateLimiter struct {
+	store  ratelimit.Store
+	limits map[string]ratelimit.Limit
+}
+
+func NewRateLimiter(store ratelimit.Store, limits map[string]ratelimit.Limit) *RateLimiter {
+	return &RateLimiter{
+		store:  store,
+		limits: limits,
+	}
+}
+
+/*
+ * Limit returns middleware enforcing the group's limit. Requests are counted
+ * per user when it runs after AuthMiddleware and per client IP otherwise.
+ * Groups without a limit, and a nil RateLimiter, let every request through.
+ * If the store fails, requests are let through rather than failing the API.
+ */
+func (l *RateLimiter) Limit(group string) gin.HandlerFunc {
+	if l == nil || !l.limits[group].Enabled() {
+		return func(c *gin.Context) {}
+	}
+	limit := l.limits[group]
+
+	return func(c *gin.Context) {
+		result, err := l.store.Take(c.Request.Context(), rateLimitKey(c, group), limit)
+		if err != nil {
+			log.Printf("Warning: Rate limit check for %s failed: %v", group, err)
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		header.Set("RateLimit-Reset", ceilSeconds(result.ResetAfter))
+
+		if !result.Allowed {
+			header.Set("Retry-After", ceilSeconds(result.RetryAfter))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": ErrRateLimited.Error()})
+			c.Abort()
+			return
+		}
+	}
+}
+
+func rateLimitKey(c *gin.Context, group string) string {
+	if userID, ok := c.Get("user_id"); ok {
+		return fmt.Sprintf("%s:user:%v", group, userID)
+	}
+	return fmt.Sprintf("%s:ip:%s", group, c.ClientIP())
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"bytecast/internal/models"
+	"bytecast/internal/services"
+)
+
+var ErrInsufficientRole = errors.New("insufficient permissions")
+
+// RoleLookup resolves a user's current role
+type RoleLookup interface {
+	UserRole(userID uint) (models.Role, error)
+}
+
+/*
+ * RequireRole only lets users holding one of the allowed roles through. It
+ * must run after AuthMiddleware. The role is read on every request rather
+ * than carried in the access token, so a demotion takes effect immediately.
+ */
+func RequireRole(roles RoleLookup, allowed ...models.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get("user_id")
+		userID, ok := value.(uint)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": ErrMissingToken.Error()})
+			c.Abort()
+			return
+		}
+
+		role, err := roles.UserRole(userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, services.ErrTokenInvalid):
+				c.JSON(http.StatusUnauthorized, gin.H{"error": ErrInvalidToken.Error()})
+			case errors.Is(err, services.ErrAccountDisabled):
+				c.JSON(http.StatusForbidden, gin.H{"error": ErrAccountDisabled.Error()})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify permissions"})
+			}
+			c.Abort()
+			return
+		}
+
+		for _, r := range allowed {
+			if role == r {
+				c.Set("user_role", role)
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": ErrInsufficientRole.Error()})
+		c.Abort()
+	}
+}
@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
 	"net/http"
 	"time"
 
@@ -38,7 +41,13 @@ func SetCookie(c *gin.Context, options CookieOptions) {
 	)
 }
 
-// SetRefreshTokenCookie sets a refresh token cookie with appropriate security settings
+const (
+	CSRFCookieName = "csrf_token"
+	CSRFHeaderName = "X-CSRF-Token"
+)
+
+// SetRefreshTokenCookie sets a refresh token cookie with appropriate security
+// settings, along with the CSRF cookie for it
 func SetRefreshTokenCookie(c *gin.Context, token string, exp time.Time, secure bool, domain string) {
 	sameSite := http.SameSiteLaxMode
 	if secure {
@@ -55,9 +64,42 @@ func SetRefreshTokenCookie(c *gin.Context, token string, exp time.Time, secure b
 		HTTPOnly: true,
 		SameSite: sameSite,
 	})
+
+	// Readable by the frontend, which echoes it in the CSRF header
+	SetCookie(c, CookieOptions{
+		Name:     CSRFCookieName,
+		Value:    CSRFToken(token),
+		MaxAge:   int(time.Until(exp).Seconds()),
+		Path:     "/",
+		Domain:   domain,
+		Secure:   secure,
+		HTTPOnly: false,
+		SameSite: sameSite,
+	})
+}
+
+/*
+ * CSRFToken derives the CSRF token for a refresh token. Binding it to the
+ * refresh token, rather than comparing the header to the cookie alone, means
+ * a cookie planted by a sibling subdomain cannot be used to forge requests.
+ */
+func CSRFToken(refreshToken string) string {
+	sum := sha256.Sum256([]byte("csrf:" + refreshToken))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
 }
 
-// ClearRefreshTokenCookie clears the refresh token cookie
+// VerifyCSRFToken checks that the request carries the CSRF header for the
+// refresh token it authenticates with. Cross-site requests can neither read
+// the cookie nor set custom headers, so they fail this check.
+func VerifyCSRFToken(c *gin.Context, refreshToken string) bool {
+	header := c.GetHeader(CSRFHeaderName)
+	if header == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(header), []byte(CSRFToken(refreshToken))) == 1
+}
+
+// ClearRefreshTokenCookie clears the refresh token cookie and its CSRF cookie
 func ClearRefreshTokenCookie(c *gin.Context, secure bool, domain string) {
 	sameSite := http.SameSiteLaxMode
 	if secure {
@@ -74,4 +116,15 @@ func ClearRefreshTokenCookie(c *gin.Context, secure bool, domain string) {
 		HTTPOnly: true,
 		SameSite: sameSite,
 	})
+
+	SetCookie(c, CookieOptions{
+		Name:     CSRFCookieName,
+		Value:    "",
+		MaxAge:   -1,
+		Path:     "/",
+		Domain:   domain,
+		Secure:   secure,
+		HTTPOnly: false,
+		SameSite: sameSite,
+	})
 } 
\ No newline at end of file
@@ -2,11 +2,14 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Timezone preferences are validated without relying on the host zoneinfo
 
 	"bytecast/configs"
 	"bytecast/internal/database"
@@ -36,6 +39,13 @@ func main() {
 	}
 	
 	logger.Println("Configuration loaded successfully")
+	
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrateCommand(cfg, os.Args[2:]); err != nil {
+			logger.Fatal("Migration failed: ", err)
+		}
+		return
+	}
 	configStatus := cfg.GetConfigStatus()
 	logger.Printf("Feature status - YouTube API: %v, PubSub: %v", 
 		configStatus.YouTubeAPIEnabled, 
@@ -94,6 +104,65 @@ func setupDatabase(cfg *configs.Config, logger *log.Logger) (*database.Connectio
 	return dbConn, nil
 }
 
+/*
+ * runMigrateCommand handles "migrate up", "migrate down [steps]" and
+ * "migrate status". Down reverts one migration unless told otherwise.
+ */
+func runMigrateCommand(cfg *configs.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up|down [steps]|status")
+	}
+	
+	dbConn, err := database.New(cfg)
+	if err != nil {
+		return err
+	}
+	defer dbConn.Close()
+	
+	migrator, err := dbConn.Migrator()
+	if err != nil {
+		return err
+	}
+	
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Applied %d migration(s)\n", applied)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+		reverted, err := migrator.Down(steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Reverted %d migration(s)\n", reverted)
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied " + status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%-30s %s\n", status.Version, status.Name, state)
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down or status", args[0])
+	}
+	
+	return nil
+}
+
 func closeDatabase(dbConn *database.Connection, logger *log.Logger) {
 	logger.Println("Closing database connection...")
 	if err := dbConn.Close(); err != nil {
@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/joho/godotenv"
@@ -13,11 +14,17 @@ import (
 )
 
 type Config struct {
-    Database  Database  `validate:"required"`
-    JWT       JWT       `validate:"required"`
-    Server    Server    `validate:"required"`
-    Superuser Superuser `validate:"required"`
-    YouTube   YouTube
+    Database     Database  `validate:"required"`
+    JWT          JWT       `validate:"required"`
+    Server       Server    `validate:"required"`
+    CORS         CORS
+    RateLimits   RateLimits
+    Registration Registration
+    Superuser    Superuser `validate:"required"`
+    YouTube      YouTube
+    Watchlists   Watchlists
+    Mail         Mail
+    Google       Google
 }
 
 type Superuser struct {
@@ -35,13 +42,48 @@ type Database struct {
 }
 
 type JWT struct {
-    Secret string `validate:"required,min=32"`
+    Algorithm        string   `validate:"oneof=HS256 RS256 EdDSA"`
+    Secret           string   // Required for HS256; with other algorithms it only verifies tokens issued before the switch
+    PrivateKeyFile   string   `validate:"required_unless=Algorithm HS256"`
+    KeyID            string   // kid of the private key, derived from the public key when empty
+    VerificationKeys []string // "kid=path" public keys of retired signing keys still accepted during rotation
+    Issuer           string   `validate:"required"`
+    Audience         string   `validate:"required"`
+    LeewaySeconds    int      `validate:"min=0"` // Clock skew tolerated when checking exp, nbf and iat
 }
 
 type Server struct {
-    Port        string `validate:"required,numeric"`
-    Environment string `validate:"required,oneof=development production"`
-    Domain      string `validate:"required"`
+    Port           string   `validate:"required,numeric"`
+    Environment    string   `validate:"required,oneof=development production"`
+    Domain         string   `validate:"required"`
+    TrustedProxies []string // Proxies whose X-Forwarded-For is believed when finding the client IP
+}
+
+// CORS lists the browser origins allowed to call the API. Origins are exact
+// ("https://app.example.com") or match any subdomain ("https://*.example.com").
+type CORS struct {
+    AllowOrigins  []string `validate:"required,dive,required"`
+    AllowMethods  []string `validate:"required"`
+    AllowHeaders  []string `validate:"required"`
+    ExposeHeaders []string
+    MaxAgeSeconds int `validate:"min=0"` // How long browsers may cache a preflight response
+}
+
+type Registration struct {
+    Mode string `validate:"oneof=open invite closed"` // invite requires a code issued by an admin
+}
+
+// RateLimits caps requests per route group
+type RateLimits struct {
+    Auth     RateLimit // Per client IP
+    API      RateLimit // Per user
+    Channels RateLimit // Per user, on top of API
+}
+
+// RateLimit allows Requests per period; zero requests disables the limit
+type RateLimit struct {
+    Requests      int `validate:"min=0"`
+    PeriodSeconds int `validate:"min=1"`
 }
 
 type YouTube struct {
@@ -50,6 +92,38 @@ type YouTube struct {
     LeaseSeconds int
 }
 
+type Watchlists struct {
+    TrashRetentionDays int `validate:"min=1"` // Days a deleted watchlist stays restorable before it is purged
+}
+
+type Mail struct {
+    Driver  string `validate:"oneof=log file smtp"`
+    From    string `validate:"required,email"`
+    Dir     string // Output directory of the file driver
+    BaseURL string `validate:"required,url"` // Frontend URL that emailed links point to
+    SMTP    SMTP
+}
+
+// Google configures "Sign in with Google", which is enabled when ClientID is set.
+// The endpoints default to Google's and can point to any OpenID Connect provider.
+type Google struct {
+    ClientID            string
+    ClientSecret        string `validate:"required_with=ClientID"`
+    RedirectURL         string `validate:"required_with=ClientID,omitempty,url"` // Backend callback registered with the provider
+    FrontendCallbackURL string `validate:"omitempty,url"`                        // Where the browser lands after signing in
+    Issuer              string `validate:"required"`
+    AuthURL             string `validate:"required,url"`
+    TokenURL            string `validate:"required,url"`
+    JWKSURL             string `validate:"required,url"`
+}
+
+type SMTP struct {
+    Host     string
+    Port     int
+    Username string
+    Password string
+}
+
 func Load() (*Config, error) {
 	if err := godotenv.Load("../../.env"); err != nil {
 		log.Printf("Note: .env file not found, using environment variables")
@@ -70,18 +144,78 @@ func Load() (*Config, error) {
             Port:     getEnvWithDefault("DB_PORT", getEnvWithDefault("POSTGRES_PORT", "5432")),
         },
         JWT: JWT{
-            Secret: getEnvWithDefault("JWT_SECRET", ""),
+            Algorithm:        getEnvWithDefault("JWT_ALGORITHM", "HS256"),
+            Secret:           getEnvWithDefault("JWT_SECRET", ""),
+            PrivateKeyFile:   getEnvWithDefault("JWT_PRIVATE_KEY_FILE", ""),
+            KeyID:            getEnvWithDefault("JWT_KEY_ID", ""),
+            VerificationKeys: getEnvList("JWT_VERIFICATION_KEYS"),
+            Issuer:           getEnvWithDefault("JWT_ISSUER", "bytecast"),
+            Audience:         getEnvWithDefault("JWT_AUDIENCE", "bytecast-api"),
+            LeewaySeconds:    getEnvInt("JWT_LEEWAY_SECONDS", 30),
         },
         Server: Server{
-            Port:        getEnvWithDefault("PORT", "8080"),
-            Environment: getEnvWithDefault("APP_ENV", "development"),
-            Domain:      getEnvWithDefault("APP_DOMAIN", "localhost"),
+            Port:           getEnvWithDefault("PORT", "8080"),
+            Environment:    getEnvWithDefault("APP_ENV", "development"),
+            Domain:         getEnvWithDefault("APP_DOMAIN", "localhost"),
+            // Private networks by default, so a reverse proxy works but clients cannot spoof their IP
+            TrustedProxies: getEnvListWithDefault("TRUSTED_PROXIES", []string{
+                "127.0.0.1/8", "::1/128", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7",
+            }),
+        },
+        CORS: CORS{
+            AllowOrigins:  getEnvListWithDefault("CORS_ALLOWED_ORIGINS", []string{"http://localhost:4200"}),
+            AllowMethods:  getEnvListWithDefault("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}),
+            AllowHeaders:  getEnvListWithDefault("CORS_ALLOWED_HEADERS", []string{"Origin", "Content-Type", "Content-Length", "Accept-Encoding", "Authorization", "X-CSRF-Token", "X-Request-ID"}),
+            ExposeHeaders: getEnvListWithDefault("CORS_EXPOSED_HEADERS", []string{"Content-Length", "X-Request-ID", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After"}),
+            MaxAgeSeconds: getEnvInt("CORS_MAX_AGE_SECONDS", 43200), // 12 hours
+        },
+        RateLimits: RateLimits{
+            Auth: RateLimit{
+                Requests:      getEnvInt("RATE_LIMIT_AUTH_REQUESTS", 60),
+                PeriodSeconds: getEnvInt("RATE_LIMIT_AUTH_PERIOD_SECONDS", 60),
+            },
+            API: RateLimit{
+                Requests:      getEnvInt("RATE_LIMIT_API_REQUESTS", 300),
+                PeriodSeconds: getEnvInt("RATE_LIMIT_API_PERIOD_SECONDS", 60),
+            },
+            Channels: RateLimit{
+                Requests:      getEnvInt("RATE_LIMIT_CHANNELS_REQUESTS", 30),
+                PeriodSeconds: getEnvInt("RATE_LIMIT_CHANNELS_PERIOD_SECONDS", 3600),
+            },
+        },
+        Registration: Registration{
+            Mode: getEnvWithDefault("REGISTRATION_MODE", "open"),
         },
         YouTube: YouTube{
             APIKey:       getEnvWithDefault("YOUTUBE_API_KEY", ""),
             CallbackURL:  getEnvWithDefault("YOUTUBE_WEBSUB_CALLBACK_URL", ""),
             LeaseSeconds: getEnvInt("YOUTUBE_WEBSUB_LEASE_SECONDS", 432000), // Default 5 days (max 10 days)
         },
+        Watchlists: Watchlists{
+            TrashRetentionDays: getEnvInt("WATCHLIST_TRASH_RETENTION_DAYS", 30),
+        },
+        Mail: Mail{
+            Driver:  getEnvWithDefault("MAIL_DRIVER", "log"),
+            From:    getEnvWithDefault("MAIL_FROM", "no-reply@example.com"),
+            Dir:     getEnvWithDefault("MAIL_DIR", "tmp/mail"),
+            BaseURL: getEnvWithDefault("MAIL_LINK_BASE_URL", "http://localhost:4200"),
+            SMTP: SMTP{
+                Host:     getEnvWithDefault("SMTP_HOST", ""),
+                Port:     getEnvInt("SMTP_PORT", 587),
+                Username: getEnvWithDefault("SMTP_USERNAME", ""),
+                Password: getEnvWithDefault("SMTP_PASSWORD", ""),
+            },
+        },
+        Google: Google{
+            ClientID:            getEnvWithDefault("GOOGLE_CLIENT_ID", ""),
+            ClientSecret:        getEnvWithDefault("GOOGLE_CLIENT_SECRET", ""),
+            RedirectURL:         getEnvWithDefault("GOOGLE_REDIRECT_URL", ""),
+            FrontendCallbackURL: getEnvWithDefault("GOOGLE_FRONTEND_CALLBACK_URL", "http://localhost:4200/auth/callback"),
+            Issuer:              getEnvWithDefault("GOOGLE_ISSUER", "https://accounts.google.com"),
+            AuthURL:             getEnvWithDefault("GOOGLE_AUTH_URL", "https://accounts.google.com/o/oauth2/v2/auth"),
+            TokenURL:            getEnvWithDefault("GOOGLE_TOKEN_URL", "https://oauth2.googleapis.com/token"),
+            JWKSURL:             getEnvWithDefault("GOOGLE_JWKS_URL", "https://www.googleapis.com/oauth2/v3/certs"),
+        },
     }
 
     if err := validateConfig(cfg); err != nil {
@@ -117,6 +251,25 @@ func getEnvInt(key string, defaultValue int) int {
     return defaultValue
 }
 
+// getEnvList splits a comma-separated variable, skipping empty entries
+func getEnvList(key string) []string {
+    var values []string
+    for _, value := range strings.Split(os.Getenv(key), ",") {
+        if value = strings.TrimSpace(value); value != "" {
+            values = append(values, value)
+        }
+    }
+    return values
+}
+
+// getEnvListWithDefault is getEnvList falling back to defaultValue when unset
+func getEnvListWithDefault(key string, defaultValue []string) []string {
+    if values := getEnvList(key); len(values) > 0 {
+        return values
+    }
+    return defaultValue
+}
+
 func validateConfig(cfg *Config) error {
     validate := validator.New()
     
@@ -124,10 +277,21 @@ func validateConfig(cfg *Config) error {
         return err
     }
     
-    if len(cfg.JWT.Secret) < 32 {
+    if cfg.JWT.Algorithm == "HS256" && len(cfg.JWT.Secret) < 32 {
         return apperrors.NewInvalidConfigError("JWT_SECRET", "must be at least 32 characters long", nil)
     }
     
+    // Credentials are always allowed for the refresh cookie, which rules out "*"
+    for _, origin := range cfg.CORS.AllowOrigins {
+        if origin == "*" {
+            return apperrors.NewInvalidConfigError("CORS_ALLOWED_ORIGINS", "cannot be * because credentials are allowed; list the origins instead", nil)
+        }
+    }
+
+    if cfg.Mail.Driver == "smtp" && cfg.Mail.SMTP.Host == "" {
+        return apperrors.NewInvalidConfigError("SMTP_HOST", "required when MAIL_DRIVER is smtp", nil)
+    }
+
     if cfg.YouTube.APIKey != "" {
         // api key is provided but callback URL is missing
         if cfg.YouTube.LeaseSeconds > 0 && cfg.YouTube.CallbackURL == "" {
@@ -152,6 +316,14 @@ func logConfigStatus(cfg *Config) {
         log.Printf("Warning: YouTube API disabled (YOUTUBE_API_KEY not set)")
     }
     
+    if cfg.Google.ClientID != "" {
+        log.Printf("Google sign-in: Enabled")
+    }
+
+    if cfg.Registration.Mode != "open" {
+        log.Printf("Registration: %s", cfg.Registration.Mode)
+    }
+
     if pubSubEnabled {
         log.Printf("YouTube PubSub: Enabled (lease seconds: %d)", cfg.YouTube.LeaseSeconds)
     } else if youtubeAPIEnabled {
@@ -160,13 +332,15 @@ func logConfigStatus(cfg *Config) {
 }
 
 type ConfigStatus struct {
-    YouTubeAPIEnabled bool
-    PubSubEnabled     bool
+    YouTubeAPIEnabled  bool
+    PubSubEnabled      bool
+    GoogleLoginEnabled bool
 }
 
 func (c *Config) GetConfigStatus() *ConfigStatus {
     status := &ConfigStatus{
-        YouTubeAPIEnabled: c.YouTube.APIKey != "",
+        YouTubeAPIEnabled:  c.YouTube.APIKey != "",
+        GoogleLoginEnabled: c.Google.ClientID != "",
     }
     status.PubSubEnabled = status.YouTubeAPIEnabled && c.YouTube.CallbackURL != ""
     return status
@@ -9,6 +9,7 @@ import (
 	"gorm.io/gorm"
 
 	"bytecast/configs"
+	"bytecast/internal/database/migrations"
 	"bytecast/internal/models"
 )
 
@@ -42,65 +43,23 @@ func (c *Connection) DB() *gorm.DB {
 	return c.db
 }
 
-// RunMigrations executes all database migrations and ensures superuser exists
-func (c *Connection) RunMigrations() error {
-    // First, run the migrations to create tables if they don't exist
-    // This is done outside of a transaction to ensure tables are created
-    if err := c.db.AutoMigrate(
-        &models.User{},
-        &models.RevokedToken{},
-        &models.Channel{},
-        &models.Watchlist{},
-        &models.HubSubscription{},
-        &models.Video{},
-    ); err != nil {
-        return fmt.Errorf("failed to run migrations: %w", err)
-    }
-
-    // Now start a transaction for the rest of the operations
-    tx := c.db.Begin()
-    if tx.Error != nil {
-        return fmt.Errorf("failed to begin transaction: %w", tx.Error)
-    }
-    defer func() {
-        if r := recover(); r != nil {
-            tx.Rollback()
-        }
-    }()
-
-    // Check if color column exists
-    var hasColorColumn bool
-    if err := tx.Raw("SELECT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'watchlists' AND column_name = 'color')").Scan(&hasColorColumn).Error; err != nil {
-        tx.Rollback()
-        return fmt.Errorf("failed to check color column: %w", err)
-    }
-
-    // If color column doesn't exist, add it as nullable first
-    if !hasColorColumn {
-        if err := tx.Exec("ALTER TABLE watchlists ADD COLUMN color VARCHAR(7)").Error; err != nil {
-            tx.Rollback()
-            return fmt.Errorf("failed to add color column: %w", err)
-        }
-
-        // Set default color for existing records
-        if err := tx.Exec("UPDATE watchlists SET color = '#3b82f6' WHERE color IS NULL").Error; err != nil {
-            tx.Rollback()
-            return fmt.Errorf("failed to set default colors: %w", err)
-        }
+// Migrator returns a migrator for the migrations embedded in the binary
+func (c *Connection) Migrator() (*Migrator, error) {
+    return NewMigrator(c.db, migrations.FS)
+}
 
-        // Make column not null
-        if err := tx.Exec("ALTER TABLE watchlists ALTER COLUMN color SET NOT NULL").Error; err != nil {
-            tx.Rollback()
-            return fmt.Errorf("failed to set color not null: %w", err)
-        }
+// RunMigrations applies pending migrations and ensures superuser exists
+func (c *Connection) RunMigrations() error {
+    migrator, err := c.Migrator()
+    if err != nil {
+        return fmt.Errorf("failed to load migrations: %w", err)
     }
 
-    // Commit the transaction for color column changes
-    if err := tx.Commit().Error; err != nil {
-        return fmt.Errorf("failed to commit color column changes: %w", err)
+    if _, err := migrator.Up(); err != nil {
+        return fmt.Errorf("failed to run migrations: %w", err)
     }
 
-    // Create superuser if it doesn't exist (outside of the previous transaction)
+    // Create superuser if it doesn't exist
     if err := c.ensureSuperuser(); err != nil {
         return fmt.Errorf("failed to ensure superuser exists: %w", err)
     }
@@ -118,7 +77,14 @@ func (c *Connection) ensureSuperuser() error {
     }
 
     if count > 0 {
-        return nil // Superuser already exists
+        // A superuser created before roles existed is promoted on startup. Both
+        // must match so a regular user who took one of them is never promoted.
+        if err := c.db.Model(&models.User{}).Where("email = ? AND username = ?",
+            c.config.Superuser.Email, c.config.Superuser.Username).
+            Update("role", models.RoleAdmin).Error; err != nil {
+            return fmt.Errorf("failed to promote superuser: %w", err)
+        }
+        return nil
     }
 
     // Generate password hash
@@ -132,6 +98,7 @@ func (c *Connection) ensureSuperuser() error {
         Email:        c.config.Superuser.Email,
         Username:     c.config.Superuser.Username,
         PasswordHash: string(hashedPassword),
+        Role:         models.RoleAdmin,
     }
 
     // Use transaction to ensure both user and default watchlist are created
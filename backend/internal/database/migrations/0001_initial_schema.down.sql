@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS watchlist_videos;
+DROP TABLE IF EXISTS watchlist_channels;
+DROP TABLE IF EXISTS youtube_videos;
+DROP TABLE IF EXISTS hub_subscriptions;
+DROP TABLE IF EXISTS watchlists;
+DROP TABLE IF EXISTS channels;
+DROP TABLE IF EXISTS revoked_tokens;
+DROP TABLE IF EXISTS users;
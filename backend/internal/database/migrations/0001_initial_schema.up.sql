@@ -0,0 +1,111 @@
+-- The schema of the last release that created tables with AutoMigrate. Every
+-- statement is guarded so databases set up by AutoMigrate adopt this version
+-- as they are; the migrations after it bring them up to date.
+
+CREATE TABLE IF NOT EXISTS users (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    email TEXT NOT NULL,
+    username VARCHAR(24) NOT NULL,
+    password_hash TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (username);
+
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    token_hash VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    user_id BIGINT NOT NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_revoked_tokens_token_hash ON revoked_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens (expires_at);
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_user_id ON revoked_tokens (user_id);
+
+CREATE TABLE IF NOT EXISTS channels (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    youtube_id VARCHAR(255) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    thumbnail_url VARCHAR(512),
+    custom_name VARCHAR(255)
+);
+CREATE INDEX IF NOT EXISTS idx_channels_deleted_at ON channels (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_channels_youtube_id ON channels (youtube_id);
+
+CREATE TABLE IF NOT EXISTS watchlists (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    user_id BIGINT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    color VARCHAR(7) NOT NULL,
+    CONSTRAINT chk_watchlists_color CHECK (color ~ '^#[a-fA-F0-9]{6}$')
+);
+CREATE INDEX IF NOT EXISTS idx_watchlists_deleted_at ON watchlists (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_watchlists_user_id ON watchlists (user_id);
+
+-- Watchlists created before colors existed get the default blue
+ALTER TABLE watchlists ADD COLUMN IF NOT EXISTS color VARCHAR(7);
+UPDATE watchlists SET color = '#3b82f6' WHERE color IS NULL;
+ALTER TABLE watchlists ALTER COLUMN color SET NOT NULL;
+ALTER TABLE watchlists DROP CONSTRAINT IF EXISTS chk_watchlists_color;
+ALTER TABLE watchlists ADD CONSTRAINT chk_watchlists_color CHECK (color ~ '^#[a-fA-F0-9]{6}$');
+
+CREATE TABLE IF NOT EXISTS hub_subscriptions (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    channel_id TEXT NOT NULL,
+    subscribed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ,
+    is_active BOOLEAN,
+    lease_seconds BIGINT,
+    secret TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_hub_subscriptions_deleted_at ON hub_subscriptions (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_hub_subscriptions_channel_id ON hub_subscriptions (channel_id);
+
+CREATE TABLE IF NOT EXISTS youtube_videos (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    youtube_id VARCHAR(255) NOT NULL,
+    channel_id BIGINT,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    thumbnail_url VARCHAR(512),
+    duration VARCHAR(32),
+    published_at TIMESTAMPTZ,
+    CONSTRAINT fk_youtube_videos_channel FOREIGN KEY (channel_id) REFERENCES channels (id)
+);
+CREATE INDEX IF NOT EXISTS idx_youtube_videos_deleted_at ON youtube_videos (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_youtube_videos_youtube_id ON youtube_videos (youtube_id);
+
+CREATE TABLE IF NOT EXISTS watchlist_channels (
+    watchlist_id BIGINT NOT NULL,
+    channel_id BIGINT NOT NULL,
+    PRIMARY KEY (watchlist_id, channel_id),
+    CONSTRAINT fk_watchlist_channels_watchlist FOREIGN KEY (watchlist_id) REFERENCES watchlists (id),
+    CONSTRAINT fk_watchlist_channels_channel FOREIGN KEY (channel_id) REFERENCES channels (id)
+);
+
+CREATE TABLE IF NOT EXISTS watchlist_videos (
+    watchlist_id BIGINT NOT NULL,
+    video_id BIGINT NOT NULL,
+    PRIMARY KEY (watchlist_id, video_id),
+    CONSTRAINT fk_watchlist_videos_watchlist FOREIGN KEY (watchlist_id) REFERENCES watchlists (id),
+    CONSTRAINT fk_watchlist_videos_video FOREIGN KEY (video_id) REFERENCES youtube_videos (id)
+);
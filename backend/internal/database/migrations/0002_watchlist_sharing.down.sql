@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_watchlists_share_slug;
+ALTER TABLE watchlists
+    DROP COLUMN IF EXISTS share_slug,
+    DROP COLUMN IF EXISTS shared_at;
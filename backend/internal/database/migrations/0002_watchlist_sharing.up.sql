@@ -0,0 +1,4 @@
+ALTER TABLE watchlists
+    ADD COLUMN IF NOT EXISTS share_slug VARCHAR(32),
+    ADD COLUMN IF NOT EXISTS shared_at TIMESTAMPTZ;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_watchlists_share_slug ON watchlists (share_slug);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS watchlist_invitations;
+DROP TABLE IF EXISTS watchlist_members;
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS watchlist_members (
+    id BIGSERIAL PRIMARY KEY,
+    watchlist_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    role VARCHAR(16) NOT NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    CONSTRAINT fk_watchlist_members_watchlist FOREIGN KEY (watchlist_id) REFERENCES watchlists (id),
+    CONSTRAINT fk_watchlist_members_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_watchlist_members_watchlist_user ON watchlist_members (watchlist_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_watchlist_members_user_id ON watchlist_members (user_id);
+
+CREATE TABLE IF NOT EXISTS watchlist_invitations (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    watchlist_id BIGINT NOT NULL,
+    inviter_id BIGINT NOT NULL,
+    invitee_id BIGINT NOT NULL,
+    role VARCHAR(16) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    CONSTRAINT fk_watchlist_invitations_watchlist FOREIGN KEY (watchlist_id) REFERENCES watchlists (id),
+    CONSTRAINT fk_watchlist_invitations_inviter FOREIGN KEY (inviter_id) REFERENCES users (id),
+    CONSTRAINT fk_watchlist_invitations_invitee FOREIGN KEY (invitee_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_watchlist_invitations_deleted_at ON watchlist_invitations (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_watchlist_invitations_watchlist_id ON watchlist_invitations (watchlist_id);
+CREATE INDEX IF NOT EXISTS idx_watchlist_invitations_invitee_id ON watchlist_invitations (invitee_id);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    identifier VARCHAR(255) NOT NULL,
+    user_id BIGINT,
+    ip_address VARCHAR(45),
+    succeeded BOOLEAN NOT NULL,
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_identifier_created ON login_attempts (identifier, created_at);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_ip_created ON login_attempts (ip_address, created_at);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_user_id ON login_attempts (user_id);
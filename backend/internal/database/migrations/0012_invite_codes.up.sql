@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS invite_codes (
+    id BIGSERIAL PRIMARY KEY,
+    prefix VARCHAR(16) NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    note VARCHAR(100),
+    max_uses BIGINT NOT NULL,
+    uses BIGINT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ,
+    created_by_id BIGINT NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invite_codes_code_hash ON invite_codes (code_hash);
+CREATE INDEX IF NOT EXISTS idx_invite_codes_created_by_id ON invite_codes (created_by_id);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS invite_code_id BIGINT;
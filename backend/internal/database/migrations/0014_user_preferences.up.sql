@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS user_preferences (
+    user_id BIGINT PRIMARY KEY,
+    timezone VARCHAR(64) NOT NULL,
+    default_watchlist_id BIGINT,
+    feed_sort VARCHAR(16) NOT NULL,
+    hide_shorts BOOLEAN NOT NULL,
+    theme VARCHAR(16) NOT NULL,
+    locale VARCHAR(35) NOT NULL,
+    notify_new_videos BOOLEAN NOT NULL,
+    notify_watchlist_invitations BOOLEAN NOT NULL,
+    updated_at TIMESTAMPTZ
+);
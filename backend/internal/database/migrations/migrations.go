@@ -0,0 +1,9 @@
+// Package migrations embeds the numbered SQL migrations for the database
+// schema. Each version has a <version>_<name>.up.sql file and, unless it
+// cannot be reverted, a matching .down.sql file.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
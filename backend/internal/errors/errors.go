@@ -103,4 +103,13 @@ func NewServiceUnavailable(message string, err error) AppError {
 		Message:    message,
 		Err:        err,
 	}
-} 
\ No newline at end of file
+}
+
+func NewTooManyRequests(message string, err error) AppError {
+	return AppError{
+		Code:       http.StatusTooManyRequests,
+		StatusText: "Too Many Requests",
+		Message:    message,
+		Err:        err,
+	}
+}
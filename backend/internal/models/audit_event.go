@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+/*
+ * AuditEvent records a security-relevant action. Events are only ever
+ * inserted: nothing updates or deletes them, not even deleting the account,
+ * so UserID may point at a user that no longer exists.
+ *
+ * UserID is the account the event belongs to and ActorID who performed it.
+ * They differ for admin actions, and ActorID is nil for events without a
+ * signed-in user, such as failed logins.
+ */
+type AuditEvent struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     *uint     `gorm:"index" json:"user_id"`
+	ActorID    *uint     `json:"actor_id"`
+	Action     string    `gorm:"size:64;index;not null" json:"action"`
+	TargetType string    `gorm:"size:32" json:"target_type,omitempty"`
+	TargetID   string    `gorm:"size:64" json:"target_id,omitempty"`
+	Detail     string    `gorm:"size:255" json:"detail,omitempty"`
+	IPAddress  string    `gorm:"size:45" json:"ip_address"`
+	UserAgent  string    `gorm:"size:512" json:"user_agent"`
+	RequestID  string    `gorm:"size:64" json:"request_id"`
+	CreatedAt  time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
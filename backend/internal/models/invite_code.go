@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+/*
+ * InviteCode lets someone register while registration is invite-only. Like
+ * personal access tokens, only the SHA-256 hash of the code is stored and
+ * Prefix keeps its first characters for telling codes apart.
+ */
+type InviteCode struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Prefix      string     `gorm:"size:16;not null" json:"prefix"`
+	CodeHash    string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	Note        string     `gorm:"size:100" json:"note"`
+	MaxUses     int        `gorm:"not null" json:"max_uses"` // Zero allows unlimited uses
+	Uses        int        `gorm:"not null;default:0" json:"uses"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	CreatedByID uint       `gorm:"index;not null" json:"created_by_id"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (InviteCode) TableName() string {
+	return "invite_codes"
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+/*
+ * LoginAttempt records a single password login, successful or not. The rows
+ * drive login throttling and are kept as an audit trail of sign-in activity.
+ *
+ * Attempts against an existing account are throttled by UserID, whichever
+ * identifier was typed. Identifier is what the client typed, lowercased, so
+ * attempts against an account that does not exist are throttled the same way.
+ */
+type LoginAttempt struct {
+	ID         uint      `gorm:"primaryKey"`
+	Identifier string    `gorm:"size:255;index:idx_login_attempts_identifier_created;not null"`
+	UserID     *uint     `gorm:"index"` // nil when the identifier matched no account
+	IPAddress  string    `gorm:"size:45;index:idx_login_attempts_ip_created"`
+	Succeeded  bool      `gorm:"not null"`
+	CreatedAt  time.Time `gorm:"autoCreateTime;index:idx_login_attempts_identifier_created;index:idx_login_attempts_ip_created"`
+}
+
+func (LoginAttempt) TableName() string {
+	return "login_attempts"
+}
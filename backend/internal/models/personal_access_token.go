@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+)
+
+// TokenScope limits what a personal access token may do
+type TokenScope string
+
+const (
+	TokenScopeRead  TokenScope = "read"  // Safe methods only: GET, HEAD and OPTIONS
+	TokenScopeWrite TokenScope = "write" // Everything personal access tokens are accepted for
+)
+
+// IsValid reports whether the scope can be granted to a token
+func (s TokenScope) IsValid() bool {
+	return s == TokenScopeRead || s == TokenScopeWrite
+}
+
+/*
+ * PersonalAccessToken is a long-lived credential for scripts and integrations.
+ * Only the SHA-256 hash of the token is stored; Prefix keeps its first
+ * characters so users can tell their tokens apart.
+ */
+type PersonalAccessToken struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"index;not null" json:"-"`
+	Name       string     `gorm:"size:100;not null" json:"name"`
+	Prefix     string     `gorm:"size:16;not null" json:"prefix"`
+	TokenHash  string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	Scope      TokenScope `gorm:"size:16;not null" json:"scope"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `gorm:"index" json:"-"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (PersonalAccessToken) TableName() string {
+	return "personal_access_tokens"
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+/*
+ * RecoveryCode is a single-use code that stands in for a TOTP code when the
+ * user has lost their authenticator. Only the SHA-256 hash is stored.
+ */
+type RecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index;not null"`
+	CodeHash  string `gorm:"size:64;not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (RecoveryCode) TableName() string {
+	return "recovery_codes"
+}
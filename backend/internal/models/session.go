@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+/*
+ * Session is a refresh token family started by a single login. Every refresh
+ * rotates the token and records the new token's ID as CurrentTokenID; a token
+ * from the family that is not the current one has already been rotated, and
+ * presenting it again revokes the whole session.
+ *
+ * The client details are captured at login and updated on every refresh so
+ * users can tell their devices apart.
+ */
+type Session struct {
+	ID             string     `gorm:"type:varchar(36);primaryKey"` // Family ID embedded in the tokens
+	UserID         uint       `gorm:"index;not null"`
+	CurrentTokenID string     `gorm:"type:varchar(36);not null"` // jti of the latest refresh token
+	UserAgent      string     `gorm:"size:512"`
+	IPAddress      string     `gorm:"size:45"`
+	LastUsedAt     time.Time  `gorm:"not null"`
+	ExpiresAt      time.Time  `gorm:"index;not null"`
+	RevokedAt      *time.Time `gorm:"index"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime"`
+}
+
+func (Session) TableName() string {
+	return "sessions"
+}
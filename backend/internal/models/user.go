@@ -1,14 +1,38 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
+// Role grants a user access beyond their own data
+type Role string
+
+const (
+	RoleUser  Role = "user"
+	RoleAdmin Role = "admin"
+)
+
+// IsValid reports whether r is a known role
+func (r Role) IsValid() bool {
+	return r == RoleUser || r == RoleAdmin
+}
+
 type User struct {
 gorm.Model
 Email        string `gorm:"uniqueIndex;not null" json:"email"`
 Username     string `gorm:"uniqueIndex;not null;size:24" json:"username"`
 PasswordHash string `gorm:"not null" json:"-"` // "-" omits from JSON responses
+TokenVersion uint   `gorm:"not null;default:0" json:"-"` // Bumped to invalidate outstanding access tokens
+EmailVerifiedAt *time.Time `json:"email_verified_at"`
+TOTPSecret      string     `gorm:"column:totp_secret;size:64" json:"-"` // Set while enrolling and once enabled
+TOTPEnabledAt   *time.Time `gorm:"column:totp_enabled_at" json:"totp_enabled_at"`
+TOTPLastCounter uint64     `gorm:"column:totp_last_counter;not null;default:0" json:"-"` // Last accepted time step, rejects replayed codes
+Identities      []UserIdentity `gorm:"foreignKey:UserID" json:"-"` // Linked external sign-in accounts
+Role            Role       `gorm:"size:16;not null;default:user" json:"role"`
+DisabledAt      *time.Time `json:"disabled_at"` // Disabled accounts cannot sign in or use any token
+InviteCodeID    *uint      `json:"-"` // The invite code the account registered with, if any
 }
 
 // TableName specifies the table name for the User model
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+/*
+ * UserIdentity links an account at an external OpenID Connect provider to a
+ * user. Subject is the provider's stable user ID; the email is only a copy of
+ * what the provider last reported.
+ */
+type UserIdentity struct {
+	ID          uint      `gorm:"primaryKey"`
+	UserID      uint      `gorm:"index;not null"`
+	Provider    string    `gorm:"size:32;uniqueIndex:idx_user_identities_provider_subject;not null"`
+	Subject     string    `gorm:"size:255;uniqueIndex:idx_user_identities_provider_subject;not null"`
+	Email       string    `gorm:"size:255"`
+	LastLoginAt time.Time `gorm:"not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+)
+
+// FeedSort orders videos in a feed by publication date
+type FeedSort string
+
+const (
+	FeedSortNewest FeedSort = "newest"
+	FeedSortOldest FeedSort = "oldest"
+)
+
+// Theme is the colour scheme of the frontend
+type Theme string
+
+const (
+	ThemeSystem Theme = "system" // Follows the operating system setting
+	ThemeLight  Theme = "light"
+	ThemeDark   Theme = "dark"
+)
+
+// NotificationSettings selects which emails the user wants
+type NotificationSettings struct {
+	NewVideos            bool `gorm:"not null" json:"new_videos"`
+	WatchlistInvitations bool `gorm:"not null" json:"watchlist_invitations"`
+}
+
+/*
+ * UserPreferences holds per-user settings. Users get a row the first time
+ * they change anything; until then DefaultUserPreferences applies. Defaults
+ * live in code rather than in column defaults, because GORM would replace
+ * false or empty values with the column default on insert.
+ *
+ * There is no video feed endpoint yet, so FeedSort, HideShorts and
+ * DefaultWatchlistID are only stored for clients to apply.
+ */
+type UserPreferences struct {
+	UserID             uint                 `gorm:"primaryKey;autoIncrement:false" json:"-"`
+	Timezone           string               `gorm:"size:64;not null" json:"timezone"` // IANA name such as "Europe/Berlin"
+	DefaultWatchlistID *uint                `json:"default_watchlist_id"`
+	FeedSort           FeedSort             `gorm:"size:16;not null" json:"feed_sort"`
+	HideShorts         bool                 `gorm:"not null" json:"hide_shorts"`
+	Theme              Theme                `gorm:"size:16;not null" json:"theme"`
+	Locale             string               `gorm:"size:35;not null" json:"locale"` // BCP 47 language tag
+	Notifications      NotificationSettings `gorm:"embedded;embeddedPrefix:notify_" json:"notifications"`
+	UpdatedAt          time.Time            `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (UserPreferences) TableName() string {
+	return "user_preferences"
+}
+
+// DefaultUserPreferences returns the settings of a user who never changed any
+func DefaultUserPreferences(userID uint) UserPreferences {
+	return UserPreferences{
+		UserID:   userID,
+		Timezone: "UTC",
+		FeedSort: FeedSortNewest,
+		Theme:    ThemeSystem,
+		Locale:   "en",
+		Notifications: NotificationSettings{
+			WatchlistInvitations: true,
+		},
+	}
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+type UserTokenPurpose string
+
+const (
+	UserTokenPasswordReset     UserTokenPurpose = "password_reset"
+	UserTokenEmailVerification UserTokenPurpose = "email_verification"
+)
+
+// UserToken is a single-use token emailed to a user. Only its SHA-256 hash is
+// stored; Email records the address the token was sent to.
+type UserToken struct {
+	ID        uint             `gorm:"primaryKey"`
+	UserID    uint             `gorm:"index;not null"`
+	Purpose   UserTokenPurpose `gorm:"type:varchar(32);index;not null"`
+	TokenHash string           `gorm:"type:varchar(64);uniqueIndex;not null"`
+	Email     string           `gorm:"not null"`
+	ExpiresAt time.Time        `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (UserToken) TableName() string {
+	return "user_tokens"
+}
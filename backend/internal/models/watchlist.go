@@ -3,6 +3,7 @@ package models
 import (
 	"fmt"
 	"regexp"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -16,8 +17,11 @@ type Watchlist struct {
 	Name        string `gorm:"size:255;not null"`
 	Description string `gorm:"type:text"`
 	Color       string `gorm:"size:7;not null;check:color ~ '^#[a-fA-F0-9]{6}$'"`
+	ShareSlug   *string    `gorm:"uniqueIndex;size:32"` // Public read-only link, nil when not shared
+	SharedAt    *time.Time
 	Channels    []*Channel `gorm:"many2many:watchlist_channels;"`
 	Videos      []*Video `gorm:"many2many:watchlist_videos;"`
+	Role        WatchlistRole `gorm:"-"` // Role of the requesting user, populated by WatchlistService
 }
 
 func (Watchlist) TableName() string {
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WatchlistRole defines what a user may do with a watchlist
+type WatchlistRole string
+
+const (
+	WatchlistRoleOwner  WatchlistRole = "owner"  // Full control, including sharing and deletion
+	WatchlistRoleEditor WatchlistRole = "editor" // Can rename the watchlist and add/remove channels
+	WatchlistRoleViewer WatchlistRole = "viewer" // Read-only access
+)
+
+var watchlistRoleRank = map[WatchlistRole]int{
+	WatchlistRoleViewer: 1,
+	WatchlistRoleEditor: 2,
+	WatchlistRoleOwner:  3,
+}
+
+// Allows reports whether the role grants at least the permissions of required
+func (r WatchlistRole) Allows(required WatchlistRole) bool {
+	return watchlistRoleRank[r] >= watchlistRoleRank[required]
+}
+
+// IsValid reports whether the role is one that can be granted to a member
+func (r WatchlistRole) IsValid() bool {
+	return r == WatchlistRoleEditor || r == WatchlistRoleViewer
+}
+
+/*
+ * WatchlistMember grants a user other than the owner access to a watchlist.
+ * The owner itself is always Watchlist.UserID and never has a member row.
+ */
+type WatchlistMember struct {
+	ID          uint          `gorm:"primaryKey"`
+	WatchlistID uint          `gorm:"uniqueIndex:idx_watchlist_members_watchlist_user;not null"`
+	UserID      uint          `gorm:"uniqueIndex:idx_watchlist_members_watchlist_user;index;not null"`
+	Role        WatchlistRole `gorm:"size:16;not null"`
+	Watchlist   Watchlist     `gorm:"foreignKey:WatchlistID"`
+	User        User          `gorm:"foreignKey:UserID"`
+	CreatedAt   time.Time     `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time     `gorm:"autoUpdateTime"`
+}
+
+func (WatchlistMember) TableName() string {
+	return "watchlist_members"
+}
+
+// InvitationStatus tracks the lifecycle of a watchlist invitation
+type InvitationStatus string
+
+const (
+	InvitationPending  InvitationStatus = "pending"
+	InvitationAccepted InvitationStatus = "accepted"
+	InvitationDeclined InvitationStatus = "declined"
+)
+
+/*
+ * WatchlistInvitation is an offer to join a watchlist with a given role,
+ * which becomes a WatchlistMember once the invitee accepts it.
+ */
+type WatchlistInvitation struct {
+	gorm.Model
+	WatchlistID uint             `gorm:"index;not null"`
+	InviterID   uint             `gorm:"not null"`
+	InviteeID   uint             `gorm:"index;not null"`
+	Role        WatchlistRole    `gorm:"size:16;not null"`
+	Status      InvitationStatus `gorm:"size:16;not null;default:pending"`
+	Watchlist   Watchlist        `gorm:"foreignKey:WatchlistID"`
+	Inviter     User             `gorm:"foreignKey:InviterID"`
+	Invitee     User             `gorm:"foreignKey:InviteeID"`
+}
+
+func (WatchlistInvitation) TableName() string {
+	return "watchlist_invitations"
+}
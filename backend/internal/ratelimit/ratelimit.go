@@ -0,0 +1,132 @@
+// Package ratelimit implements token bucket rate limiting. The bucket
+// arithmetic is kept apart from storage so that a shared store, such as
+// Redis, can back several instances with the same behaviour as MemoryStore.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit allows Requests per Period on average, in bursts of up to Requests
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Enabled reports whether the limit restricts anything
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
+
+// interval is the time it takes to regain one token
+func (l Limit) interval() time.Duration {
+	return l.Period / time.Duration(l.Requests)
+}
+
+// Result describes the bucket after a request has been counted
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration // Until the next request is allowed, zero when this one was
+	ResetAfter time.Duration // Until the bucket is full again
+}
+
+/*
+ * Store counts requests against per-key buckets. Take must be atomic per key
+ * and safe for concurrent use. A store shared between instances can persist
+ * Bucket as is and run Bucket.Take inside a transaction, or port its few
+ * lines of arithmetic to a server-side script.
+ */
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Bucket is the persisted state of one token bucket. The zero value is a
+// bucket that has never been used, which starts out full.
+type Bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// Take refills the bucket for the time elapsed since its last update and
+// spends one token if there is one
+func (b *Bucket) Take(limit Limit, now time.Time) Result {
+	capacity := float64(limit.Requests)
+	interval := float64(limit.interval())
+
+	if b.UpdatedAt.IsZero() {
+		b.Tokens = capacity
+	} else if elapsed := now.Sub(b.UpdatedAt); elapsed > 0 {
+		b.Tokens = math.Min(capacity, b.Tokens+float64(elapsed)/interval)
+	}
+	b.UpdatedAt = now
+
+	result := Result{Limit: limit.Requests}
+	if b.Tokens >= 1 {
+		b.Tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration(math.Ceil((1 - b.Tokens) * interval))
+	}
+
+	result.Remaining = int(b.Tokens)
+	result.ResetAfter = time.Duration(math.Ceil((capacity - b.Tokens) * interval))
+	return result
+}
+
+const sweepInterval = time.Minute
+
+type memoryEntry struct {
+	bucket Bucket
+	fullAt time.Time // Once full, the entry is the same as a missing one
+}
+
+// MemoryStore keeps buckets in process memory. Counts are per instance, so
+// with several instances each one allows the full limit.
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries:   make(map[string]*memoryEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		s.sweep(now)
+	}
+
+	entry, ok := s.entries[key]
+	if !ok {
+		entry = &memoryEntry{}
+		s.entries[key] = entry
+	}
+
+	result := entry.bucket.Take(limit, now)
+	entry.fullAt = now.Add(result.ResetAfter)
+	return result, nil
+}
+
+// sweep drops buckets that have refilled completely
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, entry := range s.entries {
+		if !now.Before(entry.fullAt) {
+			delete(s.entries, key)
+		}
+	}
+	s.lastSweep = now
+}
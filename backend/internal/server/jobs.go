@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	trashPurgeInterval = time.Hour
+	channelGCInterval  = 30 * time.Minute
+
+	loginAttemptPurgeInterval = 24 * time.Hour
+	loginAttemptRetention     = 90 * 24 * time.Hour
+)
+
+// startBackgroundJobs launches the periodic maintenance jobs, they stop when ctx is cancelled
+func (s *Server) startBackgroundJobs(ctx context.Context) {
+	go s.runPeriodically(ctx, "trash purge", trashPurgeInterval, s.purgeTrash)
+	go s.runPeriodically(ctx, "channel gc", channelGCInterval, s.collectChannels)
+	go s.runPeriodically(ctx, "login attempt purge", loginAttemptPurgeInterval, s.purgeLoginAttempts)
+}
+
+// runPeriodically runs job immediately and then on every tick until ctx is cancelled.
+// Errors are logged and the job is retried on the next tick.
+func (s *Server) runPeriodically(ctx context.Context, name string, interval time.Duration, job func() error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := job(); err != nil {
+			s.logger.Printf("Background job %q failed: %v", name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) purgeTrash() error {
+	retention := time.Duration(s.cfg.Watchlists.TrashRetentionDays) * 24 * time.Hour
+
+	purged, err := s.watchlistService.PurgeTrashedWatchlists(time.Now().Add(-retention))
+	if err != nil {
+		return err
+	}
+
+	if purged > 0 {
+		s.logger.Printf("Purged %d trashed watchlists", purged)
+	}
+	return nil
+}
+
+func (s *Server) collectChannels() error {
+	result, err := s.channelGCService.Run()
+	if result != nil && (result.Collected > 0 || result.Resubscribed > 0) {
+		s.logger.Printf("Channel GC collected %d channels and resubscribed %d", result.Collected, result.Resubscribed)
+	}
+	return err
+}
+
+func (s *Server) purgeLoginAttempts() error {
+	purged, err := s.authService.PurgeLoginAttempts(time.Now().Add(-loginAttemptRetention))
+	if err != nil {
+		return err
+	}
+
+	if purged > 0 {
+		s.logger.Printf("Purged %d login attempts", purged)
+	}
+	return nil
+}
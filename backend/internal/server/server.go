@@ -13,7 +13,12 @@ import (
 	"bytecast/api/middleware"
 	"bytecast/configs"
 	"bytecast/internal/database"
+	"bytecast/internal/mailer"
+	"bytecast/internal/models"
+	"bytecast/internal/oidc"
+	"bytecast/internal/ratelimit"
 	"bytecast/internal/services"
+	"bytecast/internal/token"
 )
 
 type Server struct {
@@ -24,13 +29,24 @@ type Server struct {
 	configStatus *configs.ConfigStatus
 	server       *http.Server
 	logger       *log.Logger
+	jobsCtx      context.Context
+	stopJobs     context.CancelFunc
 	
 	/* Dependencies */
-	videoService     *services.VideoService
-	youtubeService   *services.YouTubeService
-	pubsubService    *services.PubSubService
-	watchlistService *services.WatchlistService
-	authService      *services.AuthService
+	tokenKeys          *token.KeySet
+	tokenManager       *token.Manager
+	videoService       *services.VideoService
+	youtubeService     *services.YouTubeService
+	pubsubService      *services.PubSubService
+	watchlistService   *services.WatchlistService
+	channelGCService   *services.ChannelGCService
+	authService        *services.AuthService
+	accountService     *services.AccountService
+	adminService       *services.AdminService
+	auditService       *services.AuditService
+	preferencesService *services.PreferencesService
+	googleProvider     *oidc.Provider
+	rateLimiter        *middleware.RateLimiter
 }
 
 // New creates a new server instance with all dependencies injected
@@ -40,11 +56,22 @@ func New(cfg *configs.Config, db *database.Connection, logger *log.Logger) (*Ser
 	}
 
 	router := gin.New()
+	// ClientIP only trusts X-Forwarded-For from these, so per-IP limits cannot be dodged
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
 	router.Use(gin.Recovery())
 	router.Use(middleware.ErrorHandler())
 	router.Use(middleware.RequestID())
 	router.Use(middleware.Logger(logger))
-	router.Use(middleware.CORS())
+	router.Use(middleware.CORSWithConfig(&middleware.CORSConfig{
+		AllowOrigins:     cfg.CORS.AllowOrigins,
+		AllowMethods:     cfg.CORS.AllowMethods,
+		AllowHeaders:     cfg.CORS.AllowHeaders,
+		ExposeHeaders:    cfg.CORS.ExposeHeaders,
+		AllowCredentials: true,
+		MaxAge:           time.Duration(cfg.CORS.MaxAgeSeconds) * time.Second,
+	}))
 	
 	s := &Server{
 		router:      router,
@@ -59,6 +86,7 @@ func New(cfg *configs.Config, db *database.Connection, logger *log.Logger) (*Ser
 	}
 	
 	s.setupRoutes()
+	s.jobsCtx, s.stopJobs = context.WithCancel(context.Background())
 	
 	s.server = &http.Server{
 		Addr:              fmt.Sprintf("0.0.0.0:%s", cfg.Server.Port),
@@ -73,6 +101,8 @@ func New(cfg *configs.Config, db *database.Connection, logger *log.Logger) (*Ser
 }
 
 func (s *Server) Start() error {
+	s.startBackgroundJobs(s.jobsCtx)
+
 	s.logger.Printf("Starting server on port %s", s.cfg.Server.Port)
 	if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("server error: %w", err)
@@ -82,6 +112,7 @@ func (s *Server) Start() error {
 
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.logger.Println("Shutting down server...")
+	s.stopJobs()
 	return s.server.Shutdown(ctx)
 }
 
@@ -104,27 +135,98 @@ func (s *Server) initServices() error {
 	}
 	
 	s.watchlistService = services.NewWatchlistService(db, s.cfg, s.youtubeService)
+	s.channelGCService = services.NewChannelGCService(db)
+	s.watchlistService.SetChannelGC(s.channelGCService)
 	
 	if s.pubsubService != nil {
 		s.watchlistService.SetPubSubService(s.pubsubService)
+		s.channelGCService.SetPubSubService(s.pubsubService)
+	}
+	
+	keys, err := token.LoadKeySet(&s.cfg.JWT)
+	if err != nil {
+		return fmt.Errorf("failed to load JWT keys: %w", err)
 	}
+	s.tokenKeys = keys
+	s.tokenManager = token.NewManager(keys, s.cfg.JWT.Issuer, s.cfg.JWT.Audience,
+		time.Duration(s.cfg.JWT.LeewaySeconds)*time.Second)
+	s.logger.Printf("JWT signing initialized (%s)", s.cfg.JWT.Algorithm)
 	
-	s.authService = services.NewAuthService(db, s.watchlistService, s.cfg.JWT.Secret)
+	s.authService = services.NewAuthService(db, s.watchlistService, s.tokenManager)
+	s.authService.SetRegistrationMode(services.RegistrationMode(s.cfg.Registration.Mode))
+	
+	mail, err := mailer.New(&s.cfg.Mail, s.logger)
+	if err != nil {
+		return fmt.Errorf("failed to initialize mailer: %w", err)
+	}
+	s.accountService = services.NewAccountService(db, s.authService, s.watchlistService, mail, s.cfg.Mail.BaseURL)
+	s.adminService = services.NewAdminService(db, s.authService)
+	s.auditService = services.NewAuditService(db)
+	s.preferencesService = services.NewPreferencesService(db, s.watchlistService)
+	if s.pubsubService != nil {
+		s.adminService.SetPubSubService(s.pubsubService)
+	}
+	
+	s.rateLimiter = middleware.NewRateLimiter(ratelimit.NewMemoryStore(), map[string]ratelimit.Limit{
+		middleware.RateLimitAuth:     rateLimit(s.cfg.RateLimits.Auth),
+		middleware.RateLimitAPI:      rateLimit(s.cfg.RateLimits.API),
+		middleware.RateLimitChannels: rateLimit(s.cfg.RateLimits.Channels),
+	})
+	
+	if s.configStatus.GoogleLoginEnabled {
+		s.googleProvider = oidc.NewProvider("google", oidc.Config{
+			Issuer:       s.cfg.Google.Issuer,
+			ClientID:     s.cfg.Google.ClientID,
+			ClientSecret: s.cfg.Google.ClientSecret,
+			RedirectURL:  s.cfg.Google.RedirectURL,
+			AuthURL:      s.cfg.Google.AuthURL,
+			TokenURL:     s.cfg.Google.TokenURL,
+			JWKSURL:      s.cfg.Google.JWKSURL,
+		})
+		s.logger.Println("Google sign-in initialized successfully")
+	}
 	
 	return nil
 }
 
+func rateLimit(cfg configs.RateLimit) ratelimit.Limit {
+	return ratelimit.Limit{
+		Requests: cfg.Requests,
+		Period:   time.Duration(cfg.PeriodSeconds) * time.Second,
+	}
+}
+
 // Factory methods for handlers
 func (s *Server) newHealthHandler() *handler.HealthHandler {
 	return handler.NewHealthHandler(s.db, s.cfg, s.pubsubService)
 }
 
 func (s *Server) newAuthHandler() *handler.AuthHandler {
-	return handler.NewAuthHandler(s.authService, s.cfg)
+	return handler.NewAuthHandler(s.authService, s.accountService, s.auditService, s.cfg)
+}
+
+func (s *Server) newAccountHandler() *handler.AccountHandler {
+	return handler.NewAccountHandler(s.accountService, s.auditService, s.cfg)
+}
+
+func (s *Server) newAdminHandler() *handler.AdminHandler {
+	return handler.NewAdminHandler(s.adminService, s.auditService)
+}
+
+func (s *Server) newGoogleHandler() *handler.OIDCHandler {
+	return handler.NewOIDCHandler(s.authService, s.auditService, s.googleProvider, s.cfg.Google.FrontendCallbackURL, s.cfg)
+}
+
+func (s *Server) newJWKSHandler() *handler.JWKSHandler {
+	return handler.NewJWKSHandler(s.tokenKeys)
 }
 
 func (s *Server) newWatchlistHandler() *handler.WatchlistHandler {
-	return handler.NewWatchlistHandler(s.watchlistService)
+	return handler.NewWatchlistHandler(s.watchlistService, s.auditService)
+}
+
+func (s *Server) newPreferencesHandler() *handler.PreferencesHandler {
+	return handler.NewPreferencesHandler(s.preferencesService)
 }
 
 func (s *Server) newPubSubHandler() *handler.YouTubePubSubHandler {
@@ -135,12 +237,31 @@ func (s *Server) setupRoutes() {
 	healthHandler := s.newHealthHandler()
 	healthHandler.RegisterRoutes(s.router)
 	
-	authMiddleware := middleware.AuthMiddleware([]byte(s.cfg.JWT.Secret))	
+	jwksHandler := s.newJWKSHandler()
+	jwksHandler.RegisterRoutes(s.router)
+	
+	// Personal access tokens only reach the watchlist API, never the account routes
+	authMiddleware := middleware.AuthMiddleware(s.tokenManager, s.authService, nil)
+	apiAuthMiddleware := middleware.AuthMiddleware(s.tokenManager, s.authService, s.authService)
 	authHandler := s.newAuthHandler()
-	authHandler.RegisterRoutes(s.router)
+	authHandler.RegisterRoutes(s.router, authMiddleware, s.rateLimiter)
+
+	accountHandler := s.newAccountHandler()
+	accountHandler.RegisterRoutes(s.router, authMiddleware, s.rateLimiter)
+
+	if s.googleProvider != nil {
+		googleHandler := s.newGoogleHandler()
+		googleHandler.RegisterRoutes(s.router, s.rateLimiter)
+	}
+
+	adminHandler := s.newAdminHandler()
+	adminHandler.RegisterRoutes(s.router, authMiddleware, middleware.RequireRole(s.authService, models.RoleAdmin))
+
+	preferencesHandler := s.newPreferencesHandler()
+	preferencesHandler.RegisterRoutes(s.router, authMiddleware, s.rateLimiter)
 
 	watchlistHandler := s.newWatchlistHandler()
-	watchlistHandler.RegisterRoutes(s.router, authMiddleware)
+	watchlistHandler.RegisterRoutes(s.router, apiAuthMiddleware, s.rateLimiter)
 	
 	if s.pubsubService != nil {
 		pubsubHandler := s.newPubSubHandler()
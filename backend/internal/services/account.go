@@ -0,0 +1,268 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"bytecast/internal/mailer"
+	"bytecast/internal/models"
+)
+
+var (
+	ErrInvalidUserToken     = errors.New("invalid or expired token")
+	ErrEmailAlreadyVerified = errors.New("email address already verified")
+)
+
+const (
+	passwordResetTTL     = time.Hour
+	emailVerificationTTL = 24 * time.Hour
+)
+
+/*
+ * AccountService handles the emailed account flows: password resets and email
+ * verification. Each flow hands out a single-use token of which only the hash
+ * is stored, so a leaked database does not leak usable links.
+ */
+type AccountService struct {
+	db               *gorm.DB
+	authService      *AuthService
+	watchlistService *WatchlistService
+	mailer           mailer.Mailer
+	baseURL          string
+}
+
+func NewAccountService(db *gorm.DB, authService *AuthService, watchlistService *WatchlistService, mailer mailer.Mailer, baseURL string) *AccountService {
+	return &AccountService{
+		db:               db,
+		authService:      authService,
+		watchlistService: watchlistService,
+		mailer:           mailer,
+		baseURL:          strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// RequestPasswordReset emails a reset link to the account with the given
+// address. Unknown addresses and delivery failures are not reported so the
+// endpoint cannot be used to discover registered emails.
+func (s *AccountService) RequestPasswordReset(email string) error {
+	var user models.User
+	if err := s.db.Where("email = ?", email).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	token, err := s.issueToken(&user, models.UserTokenPasswordReset, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your Bytecast password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It expires in %d minutes.\n\n%s\n\n"+
+			"If you did not request a password reset, you can ignore this email.\n",
+			user.Username, int(passwordResetTTL.Minutes()), s.link("/reset-password", token)),
+	}
+	if err := s.mailer.Send(msg); err != nil {
+		log.Printf("Warning: Failed to send password reset email to user %d: %v", user.ID, err)
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password using a reset token and signs the user out
+// of every session. It returns the ID of the user whose password was reset.
+func (s *AccountService) ResetPassword(token, newPassword string) (uint, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return 0, err
+	}
+
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return 0, tx.Error
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	userToken, err := s.consumeToken(tx, token, models.UserTokenPasswordReset)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	var user models.User
+	if err := tx.First(&user, userToken.UserID).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrInvalidUserToken
+		}
+		return 0, err
+	}
+
+	updates := map[string]interface{}{"password_hash": string(hashedPassword)}
+
+	// Following the emailed link proves the address belongs to the user
+	if user.EmailVerifiedAt == nil && user.Email == userToken.Email {
+		updates["email_verified_at"] = time.Now()
+	}
+
+	if err := tx.Model(&user).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if _, err := s.authService.revokeUserSessions(tx, user.ID, ""); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+
+	s.authService.tokenVersions.Invalidate(user.ID)
+	return user.ID, nil
+}
+
+// SendVerificationEmail emails a verification link for the user's address.
+func (s *AccountService) SendVerificationEmail(userID uint) error {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return err
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+
+	token, err := s.issueToken(&user, models.UserTokenEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your Bytecast email address",
+		Body: fmt.Sprintf("Hi %s,\n\nConfirm your email address by opening the link below. It expires in %d hours.\n\n%s\n",
+			user.Username, int(emailVerificationTTL.Hours()), s.link("/verify-email", token)),
+	})
+}
+
+// VerifyEmail marks the user's address as verified. The token is only valid
+// while the account still uses the address it was sent to.
+func (s *AccountService) VerifyEmail(token string) error {
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	userToken, err := s.consumeToken(tx, token, models.UserTokenEmailVerification)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	result := tx.Model(&models.User{}).
+		Where("id = ? AND email = ?", userToken.UserID, userToken.Email).
+		Update("email_verified_at", time.Now())
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return ErrInvalidUserToken
+	}
+
+	return tx.Commit().Error
+}
+
+// issueToken creates a token for the purpose, replacing any unused one so
+// only the most recent link works.
+func (s *AccountService) issueToken(user *models.User, purpose models.UserTokenPurpose, ttl time.Duration) (string, error) {
+	token, err := generateUserToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.db.Where("user_id = ? AND purpose = ? AND used_at IS NULL", user.ID, purpose).
+		Delete(&models.UserToken{}).Error; err != nil {
+		return "", err
+	}
+
+	userToken := models.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.db.Create(&userToken).Error; err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// consumeToken marks a valid token as used within tx. Concurrent attempts to
+// use the same token are resolved by the conditional update.
+func (s *AccountService) consumeToken(tx *gorm.DB, token string, purpose models.UserTokenPurpose) (*models.UserToken, error) {
+	var userToken models.UserToken
+	if err := tx.Where("token_hash = ? AND purpose = ?", hashToken(token), purpose).First(&userToken).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidUserToken
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if userToken.UsedAt != nil || now.After(userToken.ExpiresAt) {
+		return nil, ErrInvalidUserToken
+	}
+
+	result := tx.Model(&models.UserToken{}).
+		Where("id = ? AND used_at IS NULL", userToken.ID).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInvalidUserToken
+	}
+
+	return &userToken, nil
+}
+
+func (s *AccountService) link(path, token string) string {
+	return s.baseURL + path + "?token=" + url.QueryEscape(token)
+}
+
+func generateUserToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
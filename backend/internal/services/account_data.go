@@ -0,0 +1,265 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"bytecast/internal/models"
+)
+
+// AccountExport is the archive of everything stored about a user
+type AccountExport struct {
+	ExportedAt  time.Time              `json:"exported_at"`
+	User        ExportedUser           `json:"user"`
+	Watchlists  []ExportedWatchlist    `json:"watchlists"`
+	Memberships []ExportedMember       `json:"memberships"`
+	Sessions    []ExportedSession      `json:"sessions"`
+	Identities  []ExportedIdentity     `json:"identities"`
+	Preferences models.UserPreferences `json:"preferences"`
+}
+
+type ExportedUser struct {
+	ID              uint       `json:"id"`
+	Email           string     `json:"email"`
+	Username        string     `json:"username"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	TOTPEnabledAt   *time.Time `json:"totp_enabled_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+type ExportedWatchlist struct {
+	ID          uint              `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Color       string            `json:"color"`
+	ShareSlug   *string           `json:"share_slug"`
+	CreatedAt   time.Time         `json:"created_at"`
+	DeletedAt   *time.Time        `json:"deleted_at"`
+	Channels    []ExportedChannel `json:"channels"`
+}
+
+type ExportedChannel struct {
+	YoutubeID string `json:"youtube_id"`
+	Title     string `json:"title"`
+}
+
+type ExportedMember struct {
+	WatchlistID uint                 `json:"watchlist_id"`
+	Role        models.WatchlistRole `json:"role"`
+	JoinedAt    time.Time            `json:"joined_at"`
+}
+
+type ExportedIdentity struct {
+	Provider    string    `json:"provider"`
+	Email       string    `json:"email"`
+	LinkedAt    time.Time `json:"linked_at"`
+	LastLoginAt time.Time `json:"last_login_at"`
+}
+
+type ExportedSession struct {
+	ID         string     `json:"id"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+// ExportAccount collects the user's profile, watchlists (including trashed
+// ones), memberships, sessions, linked sign-in identities and preferences.
+// Login attempts are left out.
+func (s *AccountService) ExportAccount(userID uint) (*AccountExport, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+
+	export := &AccountExport{
+		ExportedAt: time.Now().UTC(),
+		User: ExportedUser{
+			ID:              user.ID,
+			Email:           user.Email,
+			Username:        user.Username,
+			EmailVerifiedAt: user.EmailVerifiedAt,
+			TOTPEnabledAt:   user.TOTPEnabledAt,
+			CreatedAt:       user.CreatedAt,
+			UpdatedAt:       user.UpdatedAt,
+		},
+		Watchlists:  []ExportedWatchlist{},
+		Memberships: []ExportedMember{},
+		Sessions:    []ExportedSession{},
+		Identities:  []ExportedIdentity{},
+		Preferences: models.DefaultUserPreferences(userID),
+	}
+
+	if err := s.db.Where("user_id = ?", userID).Take(&export.Preferences).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var watchlists []models.Watchlist
+	if err := s.db.Unscoped().Preload("Channels").
+		Where("user_id = ?", userID).
+		Order("id").
+		Find(&watchlists).Error; err != nil {
+		return nil, err
+	}
+
+	for _, watchlist := range watchlists {
+		exported := ExportedWatchlist{
+			ID:          watchlist.ID,
+			Name:        watchlist.Name,
+			Description: watchlist.Description,
+			Color:       watchlist.Color,
+			ShareSlug:   watchlist.ShareSlug,
+			CreatedAt:   watchlist.CreatedAt,
+			Channels:    []ExportedChannel{},
+		}
+		if watchlist.DeletedAt.Valid {
+			exported.DeletedAt = &watchlist.DeletedAt.Time
+		}
+		for _, channel := range watchlist.Channels {
+			exported.Channels = append(exported.Channels, ExportedChannel{
+				YoutubeID: channel.YoutubeID,
+				Title:     channel.Title,
+			})
+		}
+		export.Watchlists = append(export.Watchlists, exported)
+	}
+
+	var members []models.WatchlistMember
+	if err := s.db.Where("user_id = ?", userID).Order("id").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	for _, member := range members {
+		export.Memberships = append(export.Memberships, ExportedMember{
+			WatchlistID: member.WatchlistID,
+			Role:        member.Role,
+			JoinedAt:    member.CreatedAt,
+		})
+	}
+
+	var sessions []models.Session
+	if err := s.db.Where("user_id = ?", userID).Order("created_at").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		export.Sessions = append(export.Sessions, ExportedSession{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			RevokedAt:  session.RevokedAt,
+		})
+	}
+
+	var identities []models.UserIdentity
+	if err := s.db.Where("user_id = ?", userID).Order("created_at").Find(&identities).Error; err != nil {
+		return nil, err
+	}
+	for _, identity := range identities {
+		export.Identities = append(export.Identities, ExportedIdentity{
+			Provider:    identity.Provider,
+			Email:       identity.Email,
+			LinkedAt:    identity.CreatedAt,
+			LastLoginAt: identity.LastLoginAt,
+		})
+	}
+
+	return export, nil
+}
+
+/*
+ * DeleteAccount permanently removes the user and everything tied to them in
+ * one transaction: owned watchlists (trashed ones included) with their join
+ * rows, memberships and invitations, sessions, revoked and emailed tokens,
+ * recovery codes, linked identities, personal access tokens, preferences
+ * and login attempts, including the ones against the user's email or
+ * username that matched no account at the time.
+ * Channels that are no longer on any watchlist are collected afterwards.
+ */
+func (s *AccountService) DeleteAccount(userID uint, password string) error {
+	user, err := s.verifyPassword(userID, password)
+	if err != nil {
+		return err
+	}
+
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	channelIDs, err := s.deleteUserData(tx, user)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	s.authService.tokenVersions.Invalidate(userID)
+	s.watchlistService.collectChannels(channelIDs)
+	return nil
+}
+
+func (s *AccountService) deleteUserData(tx *gorm.DB, user *models.User) ([]uint, error) {
+	userID := user.ID
+
+	var watchlistIDs []uint
+	if err := tx.Unscoped().Model(&models.Watchlist{}).
+		Where("user_id = ?", userID).
+		Pluck("id", &watchlistIDs).Error; err != nil {
+		return nil, err
+	}
+
+	var channelIDs []uint
+	for _, watchlistID := range watchlistIDs {
+		ids, err := deleteWatchlistRows(tx, watchlistID)
+		if err != nil {
+			return nil, err
+		}
+		channelIDs = append(channelIDs, ids...)
+	}
+
+	deletes := []struct {
+		query string
+		args  []interface{}
+	}{
+		{"DELETE FROM watchlist_members WHERE user_id = ?", []interface{}{userID}},
+		{"DELETE FROM watchlist_invitations WHERE inviter_id = ? OR invitee_id = ?", []interface{}{userID, userID}},
+		{"DELETE FROM sessions WHERE user_id = ?", []interface{}{userID}},
+		{"DELETE FROM revoked_tokens WHERE user_id = ?", []interface{}{userID}},
+		{"DELETE FROM user_tokens WHERE user_id = ?", []interface{}{userID}},
+		{"DELETE FROM recovery_codes WHERE user_id = ?", []interface{}{userID}},
+		{"DELETE FROM user_identities WHERE user_id = ?", []interface{}{userID}},
+		{"DELETE FROM personal_access_tokens WHERE user_id = ?", []interface{}{userID}},
+		{"DELETE FROM user_preferences WHERE user_id = ?", []interface{}{userID}},
+		{"DELETE FROM login_attempts WHERE user_id = ? OR identifier IN ?", []interface{}{
+			userID, []string{normalizeIdentifier(user.Email), normalizeIdentifier(user.Username)},
+		}},
+	}
+	for _, d := range deletes {
+		if err := tx.Exec(d.query, d.args...).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Unscoped().Delete(&models.User{}, userID).Error; err != nil {
+		return nil, err
+	}
+
+	return channelIDs, nil
+}
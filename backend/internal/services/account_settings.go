@@ -0,0 +1,202 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"bytecast/internal/mailer"
+	"bytecast/internal/models"
+)
+
+var (
+	ErrIncorrectPassword        = errors.New("current password is incorrect")
+	ErrReauthenticationRequired = errors.New("sign in with the identity provider again to confirm")
+)
+
+// reauthenticationWindow is how recent a provider sign-in has to be to stand
+// in for the password of an account that has none
+const reauthenticationWindow = 10 * time.Minute
+
+// ChangePassword replaces the user's password after checking the current one.
+// Accounts created through an identity provider have no password yet and set
+// their first one after a recent provider sign-in.
+func (s *AccountService) ChangePassword(userID uint, sessionID, currentPassword, newPassword string) error {
+	user, err := s.verifyPassword(userID, currentPassword)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return s.updateCredentials(user.ID, sessionID, map[string]interface{}{
+		"password_hash": string(hashedPassword),
+	})
+}
+
+// ChangeEmail moves the account to a new address, which has to be verified
+// again. The previous address is told about the change.
+func (s *AccountService) ChangeEmail(userID uint, sessionID, currentPassword, newEmail string) error {
+	user, err := s.verifyPassword(userID, currentPassword)
+	if err != nil {
+		return err
+	}
+
+	if newEmail == user.Email {
+		return nil
+	}
+
+	var existing models.User
+	if err := s.db.Where("email = ?", newEmail).First(&existing).Error; err == nil {
+		return ErrUserExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	// A concurrent change to the same address can slip past the check above
+	// and only fail on the unique index
+	oldEmail := user.Email
+	if err := s.updateCredentials(user.ID, sessionID, map[string]interface{}{
+		"email":             newEmail,
+		"email_verified_at": nil,
+	}); err != nil {
+		if isUniqueViolation(s.db, err) {
+			return ErrUserExists
+		}
+		return err
+	}
+
+	notice := mailer.Message{
+		To:      oldEmail,
+		Subject: "Your Bytecast email address was changed",
+		Body: fmt.Sprintf("Hi %s,\n\nThe email address of your account was changed to %s on %s.\n\n"+
+			"If you did not make this change, reset your password immediately.\n",
+			user.Username, newEmail, time.Now().UTC().Format(time.RFC1123)),
+	}
+	if err := s.mailer.Send(notice); err != nil {
+		log.Printf("Warning: Failed to send email change notice to user %d: %v", user.ID, err)
+	}
+
+	if err := s.SendVerificationEmail(user.ID); err != nil {
+		log.Printf("Warning: Failed to send verification email to user %d: %v", user.ID, err)
+	}
+
+	return nil
+}
+
+// ChangeUsername renames the user. Usernames are only a login identifier, so
+// existing sessions stay valid.
+func (s *AccountService) ChangeUsername(userID uint, newUsername string) (*models.User, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+
+	if newUsername == user.Username {
+		return &user, nil
+	}
+
+	var existing models.User
+	if err := s.db.Where("username = ?", newUsername).First(&existing).Error; err == nil {
+		return nil, ErrUsernameTaken
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// A concurrent rename to the same name can slip past the check above and
+	// only fail on the unique index
+	if err := s.db.Model(&user).Update("username", newUsername).Error; err != nil {
+		if isUniqueViolation(s.db, err) {
+			return nil, ErrUsernameTaken
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// isUniqueViolation reports whether err comes from a unique constraint
+func isUniqueViolation(db *gorm.DB, err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	if translator, ok := db.Dialector.(gorm.ErrorTranslator); ok {
+		return errors.Is(translator.Translate(err), gorm.ErrDuplicatedKey)
+	}
+	return false
+}
+
+/*
+ * verifyPassword confirms a sensitive change with the user's password. Users
+ * who only sign in through an identity provider have no password, so for them
+ * a provider sign-in within reauthenticationWindow counts instead, and
+ * ErrReauthenticationRequired asks them to sign in there again.
+ */
+func (s *AccountService) verifyPassword(userID uint, password string) (*models.User, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+
+	if user.PasswordHash == "" {
+		var recent int64
+		if err := s.db.Model(&models.UserIdentity{}).
+			Where("user_id = ? AND last_login_at > ?", user.ID, time.Now().Add(-reauthenticationWindow)).
+			Count(&recent).Error; err != nil {
+			return nil, err
+		}
+		if recent == 0 {
+			return nil, ErrReauthenticationRequired
+		}
+		return &user, nil
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrIncorrectPassword
+	}
+
+	return &user, nil
+}
+
+/*
+ * updateCredentials applies the updates and signs the user out everywhere
+ * except the session that made the change, in one transaction. Bumping the
+ * token version also expires that session's access token, which the client
+ * replaces through a normal refresh.
+ */
+func (s *AccountService) updateCredentials(userID uint, sessionID string, updates map[string]interface{}) error {
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := s.authService.revokeUserSessions(tx, userID, sessionID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	s.authService.tokenVersions.Invalidate(userID)
+	return nil
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"bytecast/internal/mailer"
+	"bytecast/internal/models"
+	"bytecast/internal/totp"
+)
+
+const totpIssuer = "Bytecast"
+
+// TOTPEnrollment is what the user adds to their authenticator app
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// BeginTOTPEnrollment generates a new secret for the user. It only takes
+// effect once ConfirmTOTPEnrollment has seen a code generated from it;
+// starting over replaces a pending secret.
+func (s *AccountService) BeginTOTPEnrollment(userID uint) (*TOTPEnrollment, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Model(&user).Update("totp_secret", secret).Error; err != nil {
+		return nil, err
+	}
+
+	return &TOTPEnrollment{
+		Secret: secret,
+		URI:    totp.KeyURI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTPEnrollment enables two-factor authentication once the user
+// proves their authenticator works, and returns the recovery codes. They are
+// only ever shown this once.
+func (s *AccountService) ConfirmTOTPEnrollment(userID uint, code string) ([]string, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, ErrTwoFactorEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTOTPEnrollmentMissing
+	}
+
+	counter, ok := totp.Validate(user.TOTPSecret, code, time.Now(), totpSkew)
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Model(&user).Updates(map[string]interface{}{
+		"totp_enabled_at":   time.Now(),
+		"totp_last_counter": counter,
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	codes, err := replaceRecoveryCodes(tx, user.ID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	s.notifyTwoFactorChange(&user, "enabled")
+	return codes, nil
+}
+
+// DisableTwoFactor turns two-factor authentication off after checking the
+// password, and discards the secret and recovery codes.
+func (s *AccountService) DisableTwoFactor(userID uint, password string) error {
+	user, err := s.verifyPassword(userID, password)
+	if err != nil {
+		return err
+	}
+	if user.TOTPEnabledAt == nil {
+		return ErrTwoFactorNotEnabled
+	}
+
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Model(user).Updates(map[string]interface{}{
+		"totp_secret":       "",
+		"totp_enabled_at":   nil,
+		"totp_last_counter": 0,
+	}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	s.notifyTwoFactorChange(user, "disabled")
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces all of the user's recovery codes
+func (s *AccountService) RegenerateRecoveryCodes(userID uint, password string) ([]string, error) {
+	user, err := s.verifyPassword(userID, password)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabledAt == nil {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	codes, err := replaceRecoveryCodes(tx, user.ID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// TwoFactorStatus describes the user's second factor setup
+type TwoFactorStatus struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+}
+
+func (s *AccountService) TwoFactorStatus(userID uint) (*TwoFactorStatus, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+
+	status := &TwoFactorStatus{
+		Enabled:   user.TOTPEnabledAt != nil,
+		EnabledAt: user.TOTPEnabledAt,
+	}
+	err := s.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&status.RecoveryCodesRemaining).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+func (s *AccountService) notifyTwoFactorChange(user *models.User, change string) {
+	notice := mailer.Message{
+		To:      user.Email,
+		Subject: fmt.Sprintf("Two-factor authentication %s", change),
+		Body: fmt.Sprintf("Hi %s,\n\nTwo-factor authentication was %s for your Bytecast account on %s.\n\n"+
+			"If you did not make this change, reset your password immediately.\n",
+			user.Username, change, time.Now().UTC().Format(time.RFC1123)),
+	}
+	if err := s.mailer.Send(notice); err != nil {
+		log.Printf("Warning: Failed to send two-factor notice to user %d: %v", user.ID, err)
+	}
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"bytecast/internal/models"
+)
+
+var (
+	ErrUserNotFound      = errors.New("user not found")
+	ErrCannotDisableSelf = errors.New("admins cannot disable their own account")
+	ErrPubSubDisabled    = errors.New("push notifications are not configured")
+)
+
+const maxAdminPageSize = 100
+
+/*
+ * AdminService backs the admin endpoints. It works across users, so callers
+ * must have checked the admin role; nothing here authorizes the request.
+ */
+type AdminService struct {
+	db            *gorm.DB
+	authService   *AuthService
+	pubsubService PubSubServiceInterface
+}
+
+func NewAdminService(db *gorm.DB, authService *AuthService) *AdminService {
+	return &AdminService{
+		db:          db,
+		authService: authService,
+	}
+}
+
+func (s *AdminService) SetPubSubService(pubsubService PubSubServiceInterface) {
+	s.pubsubService = pubsubService
+}
+
+// UserPage is one page of ListUsers results
+type UserPage struct {
+	Users    []models.User
+	Total    int64
+	Page     int
+	PageSize int
+}
+
+// ListUsers pages through all users, oldest first. A non-empty query matches
+// a substring of the username or email.
+func (s *AdminService) ListUsers(query string, page, pageSize int) (*UserPage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > maxAdminPageSize {
+		pageSize = maxAdminPageSize
+	}
+
+	db := s.db.Model(&models.User{})
+	if query = strings.ToLower(strings.TrimSpace(query)); query != "" {
+		pattern := "%" + query + "%"
+		db = db.Where("LOWER(username) LIKE ? OR LOWER(email) LIKE ?", pattern, pattern)
+	}
+
+	result := &UserPage{Page: page, PageSize: pageSize}
+	if err := db.Session(&gorm.Session{}).Count(&result.Total).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.Order("id").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&result.Users).Error; err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// SetUserDisabled disables or re-enables an account. Disabling revokes every
+// session and outstanding access token at once; personal access tokens are
+// refused for as long as the account stays disabled.
+func (s *AdminService) SetUserDisabled(adminID, userID uint, disabled bool) (*models.User, error) {
+	if disabled && adminID == userID {
+		return nil, ErrCannotDisableSelf
+	}
+
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var user models.User
+	if err := tx.First(&user, userID).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	// Repeating the current state is a no-op so the original timestamp is kept
+	if (user.DisabledAt != nil) == disabled {
+		tx.Rollback()
+		return &user, nil
+	}
+
+	var disabledAt *time.Time
+	if disabled {
+		now := time.Now()
+		disabledAt = &now
+	}
+	if err := tx.Model(&user).Update("disabled_at", disabledAt).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if disabled {
+		if _, err := s.authService.revokeUserSessions(tx, user.ID, ""); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	s.authService.tokenVersions.Invalidate(user.ID)
+	user.DisabledAt = disabledAt
+	return &user, nil
+}
+
+// GetWatchlist loads any watchlist with its channels, regardless of owner.
+// Watchlists in the trash are included.
+func (s *AdminService) GetWatchlist(watchlistID uint) (*models.Watchlist, error) {
+	var watchlist models.Watchlist
+	if err := s.db.Unscoped().Preload("Channels").First(&watchlist, watchlistID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWatchlistNotFound
+		}
+		return nil, err
+	}
+	return &watchlist, nil
+}
+
+// ResubscribeChannel renews the hub subscription for a channel, whatever state
+// the stored subscription is in.
+func (s *AdminService) ResubscribeChannel(youtubeID string) error {
+	if s.pubsubService == nil {
+		return ErrPubSubDisabled
+	}
+
+	var channel models.Channel
+	if err := s.db.Where("youtube_id = ?", youtubeID).First(&channel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrChannelNotFound
+		}
+		return err
+	}
+
+	return s.pubsubService.SubscribeToChannel(channel.YoutubeID)
+}
+
+// ResubscribeAllChannels renews the hub subscription of every channel that is
+// in at least one watchlist. It returns how many succeeded.
+func (s *AdminService) ResubscribeAllChannels() (int, error) {
+	if s.pubsubService == nil {
+		return 0, ErrPubSubDisabled
+	}
+
+	var channels []models.Channel
+	if err := s.db.Where("id IN (SELECT channel_id FROM watchlist_channels)").Find(&channels).Error; err != nil {
+		return 0, fmt.Errorf("failed to find channels: %w", err)
+	}
+
+	resubscribed := 0
+	var errs []error
+	for _, channel := range channels {
+		if err := s.pubsubService.SubscribeToChannel(channel.YoutubeID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to resubscribe to channel %s: %w", channel.YoutubeID, err))
+			continue
+		}
+		resubscribed++
+	}
+
+	return resubscribed, errors.Join(errs...)
+}
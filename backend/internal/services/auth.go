@@ -5,12 +5,14 @@ import (
 	"encoding/hex"
 	"errors"
 	"time"
+	"unicode/utf8"
 
-	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 
 	"bytecast/internal/models"
+	"bytecast/internal/token"
 )
 
 var (
@@ -19,32 +21,52 @@ var (
     ErrUsernameTaken      = errors.New("username already taken")
     ErrTokenInvalid       = errors.New("invalid token")
     ErrTokenRevoked       = errors.New("token has been revoked")
+    ErrTokenReused        = errors.New("refresh token has already been used")
+    ErrSessionNotFound    = errors.New("session not found")
+    ErrAccountDisabled    = errors.New("account has been disabled")
 )
 
+const maxUserAgentLength = 512
+
 type TokenPair struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 }
 
+// ClientInfo describes the client a session was started or refreshed from.
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+	RequestID string // Only kept in the audit log
+}
+
 type AuthService struct {
-    db            *gorm.DB
-    watchlistSvc  *WatchlistService
-    jwtSecret     []byte
-    accessExp     time.Duration
-    refreshExp    time.Duration
+    db               *gorm.DB
+    watchlistSvc     *WatchlistService
+    tokens           *token.Manager
+    accessExp        time.Duration
+    refreshExp       time.Duration
+    tokenVersions    *TokenVersionCache
+    throttle         LoginThrottle
+    registrationMode RegistrationMode
 }
 
-func NewAuthService(db *gorm.DB, watchlistSvc *WatchlistService, jwtSecret string) *AuthService {
+func NewAuthService(db *gorm.DB, watchlistSvc *WatchlistService, tokens *token.Manager) *AuthService {
     return &AuthService{
-        db:           db,
-        watchlistSvc: watchlistSvc,
-        jwtSecret:    []byte(jwtSecret),
-        accessExp:    15 * time.Minute,   // 15 minutes
-        refreshExp:   7 * 24 * time.Hour, // 7 days
+        db:               db,
+        watchlistSvc:     watchlistSvc,
+        tokens:           tokens,
+        accessExp:        15 * time.Minute,   // 15 minutes
+        refreshExp:       7 * 24 * time.Hour, // 7 days
+        tokenVersions:    NewTokenVersionCache(db, 30*time.Second),
+        throttle:         DefaultLoginThrottle,
+        registrationMode: RegistrationOpen,
     }
 }
 
-func (s *AuthService) RegisterUser(email, username, password string) error {
+// RegisterUser creates an account if the registration mode allows it. The
+// invite code is only looked at when registration is invite-only.
+func (s *AuthService) RegisterUser(email, username, password, inviteCode string) error {
     tx := s.db.Begin()
     if tx.Error != nil {
         return tx.Error
@@ -57,6 +79,13 @@ func (s *AuthService) RegisterUser(email, username, password string) error {
         }
     }()
 
+    // Checked first so that nobody without an invite can probe for registered emails
+    inviteCodeID, err := s.checkRegistration(tx, inviteCode)
+    if err != nil {
+        tx.Rollback()
+        return err
+    }
+
     // Check if email exists
     var existingUser models.User
     if err := tx.Where("email = ?", email).First(&existingUser).Error; err == nil {
@@ -86,6 +115,7 @@ func (s *AuthService) RegisterUser(email, username, password string) error {
         Email:        email,
         Username:     username,
         PasswordHash: string(hashedPassword),
+        InviteCodeID: inviteCodeID,
     }
 
     if err := tx.Create(&user).Error; err != nil {
@@ -93,12 +123,12 @@ func (s *AuthService) RegisterUser(email, username, password string) error {
         return err
     }
 
-    if err := s.watchlistSvc.CreateDefaultWatchlist(user.ID); err != nil {
-        tx.Rollback()
+    if err := tx.Commit().Error; err != nil {
         return err
     }
 
-    return tx.Commit().Error
+    // The watchlist service has its own connection, which cannot see the user until it is committed
+    return s.watchlistSvc.CreateDefaultWatchlist(user.ID)
 }
 
 func (s *AuthService) FindByIdentifier(identifier string) (*models.User, error) {
@@ -113,91 +143,193 @@ func (s *AuthService) FindByIdentifier(identifier string) (*models.User, error)
     return &user, nil
 }
 
-func (s *AuthService) LoginUser(identifier, password string) (*TokenPair, time.Time, error) {
+// LoginUser checks the credentials and starts a session. Unknown accounts and
+// wrong passwords both return ErrInvalidCredentials and are throttled alike;
+// a throttled attempt returns a *LoginThrottledError without checking the password.
+// Accounts with two-factor authentication get a *TwoFactorRequiredError instead
+// of tokens.
+func (s *AuthService) LoginUser(identifier, password string, client ClientInfo) (*TokenPair, time.Time, error) {
+    key := normalizeIdentifier(identifier)
     user, err := s.FindByIdentifier(identifier)
-    if err != nil {
+    if err != nil && !errors.Is(err, ErrInvalidCredentials) {
         return nil, time.Time{}, err
     }
 
+    var userID *uint
+    if user != nil {
+        userID = &user.ID
+    }
+    if err := s.checkLoginThrottle(userID, key, client.IPAddress); err != nil {
+        return nil, time.Time{}, err
+    }
+
+    if user == nil {
+        compareDummyHash(password)
+        if err := s.recordLoginAttempt(key, nil, client.IPAddress, false); err != nil {
+            return nil, time.Time{}, err
+        }
+        return nil, time.Time{}, ErrInvalidCredentials
+    }
+
     if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+        if err := s.recordLoginAttempt(key, &user.ID, client.IPAddress, false); err != nil {
+            return nil, time.Time{}, err
+        }
         return nil, time.Time{}, ErrInvalidCredentials
     }
 
-    return s.generateTokenPair(user.ID)
+    // The outcome is recorded once the second factor has been checked
+    if user.TOTPEnabledAt != nil {
+        return nil, time.Time{}, s.twoFactorChallenge(user)
+    }
+
+    if err := s.recordLoginAttempt(key, &user.ID, client.IPAddress, true); err != nil {
+        return nil, time.Time{}, err
+    }
+    return s.startSession(user, client)
 }
 
-func (s *AuthService) RefreshTokens(refreshToken string) (*TokenPair, time.Time, error) {
-    token, err := jwt.Parse(refreshToken, func(token *jwt.Token) (interface{}, error) {
-        if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-            return nil, ErrTokenInvalid
+// RefreshTokens rotates a refresh token within its family. Presenting a token
+// that has already been rotated means it leaked, so the whole family is revoked.
+func (s *AuthService) RefreshTokens(refreshToken string, client ClientInfo) (*TokenPair, time.Time, error) {
+    claims, err := s.parseRefreshToken(refreshToken)
+    if err != nil {
+        return nil, time.Time{}, err
+    }
+
+    var session models.Session
+    if err := s.db.Where("id = ? AND user_id = ?", claims.FamilyID, claims.UserID).First(&session).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return nil, time.Time{}, ErrTokenInvalid
         }
-        return s.jwtSecret, nil
-    })
+        return nil, time.Time{}, err
+    }
 
-    if err != nil || !token.Valid {
-        return nil, time.Time{}, ErrTokenInvalid
+    if session.RevokedAt != nil {
+        return nil, time.Time{}, ErrTokenRevoked
     }
 
-    claims, ok := token.Claims.(jwt.MapClaims)
-    if !ok {
-        return nil, time.Time{}, ErrTokenInvalid
+    if session.CurrentTokenID != claims.TokenID {
+        if err := s.revokeSession(session.ID); err != nil {
+            return nil, time.Time{}, err
+        }
+        return nil, time.Time{}, ErrTokenReused
     }
 
-    isRevoked, err := s.IsTokenRevoked(refreshToken)
-    if err != nil {
+    var user models.User
+    if err := s.db.Select("id", "token_version", "disabled_at").First(&user, claims.UserID).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return nil, time.Time{}, ErrTokenInvalid
+        }
         return nil, time.Time{}, err
     }
-    if isRevoked {
-        return nil, time.Time{}, ErrTokenRevoked
+
+    if user.DisabledAt != nil {
+        return nil, time.Time{}, ErrAccountDisabled
     }
 
-    userID, ok := claims["user_id"].(float64)
-    if !ok {
-        return nil, time.Time{}, ErrTokenInvalid
+    tokens, tokenID, exp, err := s.generateTokenPair(user.ID, user.TokenVersion, session.ID)
+    if err != nil {
+        return nil, time.Time{}, err
     }
 
-    return s.generateTokenPair(uint(userID))
-}
+    tx := s.db.Begin()
+    if tx.Error != nil {
+        return nil, time.Time{}, tx.Error
+    }
 
-func (s *AuthService) RevokeToken(token string) error {
-    parsedToken, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
-        if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-            return nil, ErrTokenInvalid
+    defer func() {
+        if r := recover(); r != nil {
+            tx.Rollback()
         }
-        return s.jwtSecret, nil
-    })
+    }()
 
-    if err != nil || !parsedToken.Valid {
-        return ErrTokenInvalid
+    // Only one refresh may rotate a given token; a concurrent request that
+    // loses the race is treated as reuse
+    result := tx.Model(&models.Session{}).
+        Where("id = ? AND current_token_id = ? AND revoked_at IS NULL", session.ID, claims.TokenID).
+        Updates(map[string]interface{}{
+            "current_token_id": tokenID,
+            "user_agent":       truncateUserAgent(client.UserAgent),
+            "ip_address":       client.IPAddress,
+            "last_used_at":     time.Now(),
+            "expires_at":       exp,
+        })
+    if result.Error != nil {
+        tx.Rollback()
+        return nil, time.Time{}, result.Error
+    }
+    if result.RowsAffected == 0 {
+        tx.Rollback()
+        if err := s.revokeSession(session.ID); err != nil {
+            return nil, time.Time{}, err
+        }
+        return nil, time.Time{}, ErrTokenReused
     }
 
-    claims, ok := parsedToken.Claims.(jwt.MapClaims)
-    if !ok {
-        return ErrTokenInvalid
+    revokedToken := models.RevokedToken{
+        TokenHash: hashToken(refreshToken),
+        ExpiresAt: claims.ExpiresAt,
+        UserID:    claims.UserID,
+    }
+    if err := tx.Create(&revokedToken).Error; err != nil {
+        tx.Rollback()
+        return nil, time.Time{}, err
     }
 
-    userID, ok := claims["user_id"].(float64)
-    if !ok {
-        return ErrTokenInvalid
+    if err := tx.Commit().Error; err != nil {
+        return nil, time.Time{}, err
     }
 
-    exp, ok := claims["exp"].(float64)
-    if !ok {
-        return ErrTokenInvalid
+    return tokens, exp, nil
+}
+
+// RevokeToken blacklists the refresh token and ends the session it belongs to.
+func (s *AuthService) RevokeToken(token string) error {
+    claims, err := s.parseRefreshToken(token)
+    if err != nil {
+        return err
+    }
+
+    tx := s.db.Begin()
+    if tx.Error != nil {
+        return tx.Error
+    }
+
+    defer func() {
+        if r := recover(); r != nil {
+            tx.Rollback()
+        }
+    }()
+
+    result := tx.Model(&models.Session{}).
+        Where("id = ? AND user_id = ? AND revoked_at IS NULL", claims.FamilyID, claims.UserID).
+        Update("revoked_at", time.Now())
+    if result.Error != nil {
+        tx.Rollback()
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        tx.Rollback()
+        return ErrTokenRevoked
     }
 
     revokedToken := models.RevokedToken{
-        TokenHash: s.hashToken(token),
-        ExpiresAt: time.Unix(int64(exp), 0),
-        UserID:    uint(userID),
+        TokenHash: hashToken(token),
+        ExpiresAt: claims.ExpiresAt,
+        UserID:    claims.UserID,
+    }
+    if err := tx.Create(&revokedToken).Error; err != nil {
+        tx.Rollback()
+        return err
     }
 
-    return s.db.Create(&revokedToken).Error
+    return tx.Commit().Error
 }
 
 func (s *AuthService) IsTokenRevoked(token string) (bool, error) {
     var revokedToken models.RevokedToken
-    result := s.db.Where("token_hash = ?", s.hashToken(token)).First(&revokedToken)
+    result := s.db.Where("token_hash = ?", hashToken(token)).First(&revokedToken)
 
     if result.Error == nil {
         return true, nil
@@ -210,40 +342,227 @@ func (s *AuthService) IsTokenRevoked(token string) (bool, error) {
     return false, result.Error
 }
 
-func (s *AuthService) hashToken(token string) string {
+// hashToken returns the SHA-256 hex digest under which tokens are stored.
+func hashToken(token string) string {
     hash := sha256.Sum256([]byte(token))
     return hex.EncodeToString(hash[:])
 }
 
-func (s *AuthService) generateTokenPair(userID uint) (*TokenPair, time.Time, error) {
-    accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-        "user_id": userID,
-        "exp":     time.Now().Add(s.accessExp).Unix(),
-        "type":    "access",
-    })
+// RefreshTokenUserID returns the user a validly signed refresh token was
+// issued to, whether or not it has been revoked since
+func (s *AuthService) RefreshTokenUserID(refreshToken string) (uint, bool) {
+    claims, err := s.parseRefreshToken(refreshToken)
+    if err != nil {
+        return 0, false
+    }
+    return claims.UserID, true
+}
+
+// refreshClaims holds the validated claims of a refresh token.
+type refreshClaims struct {
+    UserID    uint
+    TokenID   string
+    FamilyID  string
+    ExpiresAt time.Time
+}
 
-    accessTokenString, err := accessToken.SignedString(s.jwtSecret)
+func (s *AuthService) parseRefreshToken(tokenString string) (*refreshClaims, error) {
+    claims, err := s.tokens.Parse(tokenString, token.TypeRefresh)
     if err != nil {
-        return nil, time.Time{}, err
+        return nil, ErrTokenInvalid
     }
 
-    refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-        "user_id": userID,
-        "exp":     time.Now().Add(s.refreshExp).Unix(),
-        "type":    "refresh",
-    })
+    userID, err := claims.UserID()
+    if err != nil {
+        return nil, ErrTokenInvalid
+    }
+
+    if claims.FamilyID == "" {
+        return nil, ErrTokenInvalid
+    }
+
+    return &refreshClaims{
+        UserID:    userID,
+        TokenID:   claims.ID,
+        FamilyID:  claims.FamilyID,
+        ExpiresAt: claims.ExpiresAt.Time,
+    }, nil
+}
+
+// startSession opens a new token family for the user and issues its first pair.
+// Every sign-in method ends here, so disabled accounts are turned away here too.
+func (s *AuthService) startSession(user *models.User, client ClientInfo) (*TokenPair, time.Time, error) {
+    if user.DisabledAt != nil {
+        return nil, time.Time{}, ErrAccountDisabled
+    }
+
+    familyID := uuid.NewString()
 
-    refreshTokenString, err := refreshToken.SignedString(s.jwtSecret)
+    tokens, tokenID, exp, err := s.generateTokenPair(user.ID, user.TokenVersion, familyID)
     if err != nil {
         return nil, time.Time{}, err
     }
 
-    exp := time.Now().Add(s.refreshExp)
+    session := models.Session{
+        ID:             familyID,
+        UserID:         user.ID,
+        CurrentTokenID: tokenID,
+        UserAgent:      truncateUserAgent(client.UserAgent),
+        IPAddress:      client.IPAddress,
+        LastUsedAt:     time.Now(),
+        ExpiresAt:      exp,
+    }
+    if err := s.db.Create(&session).Error; err != nil {
+        return nil, time.Time{}, err
+    }
+
+    return tokens, exp, nil
+}
+
+func (s *AuthService) revokeSession(sessionID string) error {
+    return s.db.Model(&models.Session{}).
+        Where("id = ? AND revoked_at IS NULL", sessionID).
+        Update("revoked_at", time.Now()).Error
+}
+
+// GetSessions returns the user's sessions that are neither revoked nor expired,
+// most recently used first.
+func (s *AuthService) GetSessions(userID uint) ([]models.Session, error) {
+    var sessions []models.Session
+    err := s.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+        Order("last_used_at DESC").
+        Find(&sessions).Error
+    if err != nil {
+        return nil, err
+    }
+    return sessions, nil
+}
+
+// RevokeSession ends one of the user's active sessions.
+func (s *AuthService) RevokeSession(userID uint, sessionID string) error {
+    result := s.db.Model(&models.Session{}).
+        Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+        Update("revoked_at", time.Now())
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        return ErrSessionNotFound
+    }
+    return nil
+}
+
+// RevokeAllSessions ends every active session of the user and invalidates their
+// outstanding access tokens. It returns how many sessions were revoked.
+func (s *AuthService) RevokeAllSessions(userID uint) (int64, error) {
+    tx := s.db.Begin()
+    if tx.Error != nil {
+        return 0, tx.Error
+    }
+
+    defer func() {
+        if r := recover(); r != nil {
+            tx.Rollback()
+        }
+    }()
+
+    revoked, err := s.revokeUserSessions(tx, userID, "")
+    if err != nil {
+        tx.Rollback()
+        return 0, err
+    }
+
+    if err := tx.Commit().Error; err != nil {
+        return 0, err
+    }
+
+    s.tokenVersions.Invalidate(userID)
+    return revoked, nil
+}
+
+// revokeUserSessions revokes the user's sessions, except keepSessionID when
+// set, and bumps their token version within tx. Callers must invalidate the
+// cached version once tx commits.
+func (s *AuthService) revokeUserSessions(tx *gorm.DB, userID uint, keepSessionID string) (int64, error) {
+    query := tx.Model(&models.Session{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+    if keepSessionID != "" {
+        query = query.Where("id <> ?", keepSessionID)
+    }
+
+    result := query.Update("revoked_at", time.Now())
+    if result.Error != nil {
+        return 0, result.Error
+    }
+
+    if err := bumpTokenVersion(tx, userID); err != nil {
+        return 0, err
+    }
+
+    return result.RowsAffected, nil
+}
+
+// TokenVersion returns the user's current token version. Access tokens carrying
+// an older version are no longer accepted.
+func (s *AuthService) TokenVersion(userID uint) (uint, error) {
+    return s.tokenVersions.Get(userID)
+}
+
+// BumpTokenVersion invalidates every access token issued to the user so far.
+func (s *AuthService) BumpTokenVersion(userID uint) error {
+    if err := bumpTokenVersion(s.db, userID); err != nil {
+        return err
+    }
+
+    s.tokenVersions.Invalidate(userID)
+    return nil
+}
+
+func truncateUserAgent(userAgent string) string {
+    if utf8.RuneCountInString(userAgent) > maxUserAgentLength {
+        return string([]rune(userAgent)[:maxUserAgentLength])
+    }
+    return userAgent
+}
+
+// generateTokenPair signs an access/refresh pair for the given token family and
+// returns the ID of the new refresh token alongside its expiry.
+func (s *AuthService) generateTokenPair(userID, tokenVersion uint, familyID string) (*TokenPair, string, time.Time, error) {
+    accessToken, _, err := s.tokens.Issue(userID, token.TypeAccess, s.accessExp, token.Claims{
+        FamilyID:     familyID,
+        TokenVersion: tokenVersion,
+    })
+    if err != nil {
+        return nil, "", time.Time{}, err
+    }
+
+    refreshToken, issued, err := s.tokens.Issue(userID, token.TypeRefresh, s.refreshExp, token.Claims{
+        FamilyID: familyID,
+    })
+    if err != nil {
+        return nil, "", time.Time{}, err
+    }
 
     return &TokenPair{
-        AccessToken:  accessTokenString,
-        RefreshToken: refreshTokenString,
-    }, exp, nil
+        AccessToken:  accessToken,
+        RefreshToken: refreshToken,
+    }, issued.ID, issued.ExpiresAt.Time, nil
+}
+
+// UserRole returns the user's role. Disabled and deleted accounts get
+// ErrAccountDisabled and ErrTokenInvalid respectively.
+func (s *AuthService) UserRole(userID uint) (models.Role, error) {
+    var user models.User
+    if err := s.db.Select("id", "role", "disabled_at").First(&user, userID).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return "", ErrTokenInvalid
+        }
+        return "", err
+    }
+
+    if user.DisabledAt != nil {
+        return "", ErrAccountDisabled
+    }
+    return user.Role, nil
 }
 
 func (s *AuthService) GetUserByID(id uint) (*models.User, error) {
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+
+	"bytecast/internal/models"
+)
+
+var ErrIdentityEmailUnverified = errors.New("identity provider did not report a verified email address")
+
+const usernameAttempts = 5
+
+// ExternalIdentity is a user as reported by an external sign-in provider
+type ExternalIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+/*
+ * LoginWithIdentity signs in the user linked to the external identity. An
+ * identity seen for the first time is linked to the account with the same
+ * email address, or gets a new account, but only if the provider verified
+ * the address and registration is open. Accounts with two-factor authentication still get a challenge.
+ */
+func (s *AuthService) LoginWithIdentity(identity ExternalIdentity, client ClientInfo) (*TokenPair, time.Time, error) {
+	user, err := s.resolveIdentity(identity)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	if user.TOTPEnabledAt != nil {
+		return nil, time.Time{}, s.twoFactorChallenge(user)
+	}
+	return s.startSession(user, client)
+}
+
+func (s *AuthService) resolveIdentity(identity ExternalIdentity) (*models.User, error) {
+	var linked models.UserIdentity
+	err := s.db.Where("provider = ? AND subject = ?", identity.Provider, identity.Subject).First(&linked).Error
+	if err == nil {
+		if err := s.db.Model(&linked).Updates(map[string]interface{}{
+			"email":         identity.Email,
+			"last_login_at": time.Now(),
+		}).Error; err != nil {
+			return nil, err
+		}
+		return s.GetUserByID(linked.UserID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, ErrIdentityEmailUnverified
+	}
+
+	var user models.User
+	err = s.db.Where("LOWER(email) = LOWER(?)", identity.Email).First(&user).Error
+	switch {
+	case err == nil:
+		// Disabled accounts must not be changed, let alone taken over
+		if user.DisabledAt != nil {
+			return nil, ErrAccountDisabled
+		}
+		return s.linkIdentity(&user, identity)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return s.createIdentityUser(identity)
+	default:
+		return nil, err
+	}
+}
+
+/*
+ * linkIdentity attaches the identity to an existing account with the same
+ * email. If that account never verified its address, whoever registered it
+ * did not prove they own it, so its password is cleared and its sessions are
+ * signed out before the provider's user takes it over.
+ */
+func (s *AuthService) linkIdentity(user *models.User, identity ExternalIdentity) (*models.User, error) {
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.Create(&models.UserIdentity{
+		UserID:      user.ID,
+		Provider:    identity.Provider,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		LastLoginAt: time.Now(),
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	takenOver := user.EmailVerifiedAt == nil
+	if takenOver {
+		if err := tx.Model(user).Updates(map[string]interface{}{
+			"password_hash":     "",
+			"email_verified_at": time.Now(),
+		}).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if _, err := s.revokeUserSessions(tx, user.ID, ""); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	if takenOver {
+		s.tokenVersions.Invalidate(user.ID)
+		return s.GetUserByID(user.ID)
+	}
+	return user, nil
+}
+
+// createIdentityUser registers a new account without a password for the
+// identity. A password can be set later through a password reset, or through
+// ChangePassword shortly after a provider sign-in.
+func (s *AuthService) createIdentityUser(identity ExternalIdentity) (*models.User, error) {
+	// Provider sign-in has no way to present an invite code
+	if s.registrationMode != RegistrationOpen {
+		return nil, ErrRegistrationClosed
+	}
+
+	username, err := s.availableUsername(identity.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	now := time.Now()
+	user := models.User{
+		Email:           identity.Email,
+		Username:        username,
+		EmailVerifiedAt: &now,
+	}
+	if err := tx.Create(&user).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Create(&models.UserIdentity{
+		UserID:      user.ID,
+		Provider:    identity.Provider,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		LastLoginAt: now,
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.watchlistSvc.CreateDefaultWatchlist(user.ID); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// availableUsername derives an unused username from the local part of the
+// email, appending digits when it is taken.
+func (s *AuthService) availableUsername(email string) (string, error) {
+	local, _, _ := strings.Cut(email, "@")
+
+	var b strings.Builder
+	for _, r := range local {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+	base := b.String()
+	if len(base) < 3 {
+		base = "user" + base
+	}
+	if len(base) > 19 {
+		base = base[:19]
+	}
+
+	candidate := base
+	for i := 0; i < usernameAttempts; i++ {
+		var count int64
+		if err := s.db.Model(&models.User{}).Where("username = ?", candidate).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+
+		n, err := rand.Int(rand.Reader, big.NewInt(10000))
+		if err != nil {
+			return "", err
+		}
+		candidate = fmt.Sprintf("%s%04d", base, n.Int64())
+	}
+
+	return "", ErrUsernameTaken
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"bytecast/internal/models"
+)
+
+/*
+ * ChannelGCService keeps channels and their WebSub subscriptions in line with
+ * watchlist references. A channel is referenced while any watchlist_channels row
+ * points at it, including rows of trashed watchlists that may still be restored.
+ * Unreferenced channels are unsubscribed and soft-deleted together with their
+ * videos, and referenced channels without an active subscription are
+ * resubscribed. Every pass is idempotent, so anything that fails is simply
+ * retried on the next run.
+ */
+type ChannelGCService struct {
+	db            *gorm.DB
+	pubsubService PubSubServiceInterface
+}
+
+// ChannelGCResult summarises a reconciliation pass
+type ChannelGCResult struct {
+	Collected    int // Unreferenced channels that were cleaned up
+	Resubscribed int // Referenced channels that were missing an active subscription
+}
+
+func NewChannelGCService(db *gorm.DB) *ChannelGCService {
+	return &ChannelGCService{
+		db:            db,
+		pubsubService: nil, // later via SetPubSubService if available
+	}
+}
+
+func (s *ChannelGCService) SetPubSubService(pubsubService PubSubServiceInterface) {
+	s.pubsubService = pubsubService
+}
+
+// Run performs a full reconciliation of all channels
+func (s *ChannelGCService) Run() (*ChannelGCResult, error) {
+	result := &ChannelGCResult{}
+	var errs []error
+
+	var unreferenced []models.Channel
+	if err := s.db.Where("id NOT IN (SELECT channel_id FROM watchlist_channels)").Find(&unreferenced).Error; err != nil {
+		return nil, fmt.Errorf("failed to find unreferenced channels: %w", err)
+	}
+
+	for i := range unreferenced {
+		collected, err := s.collect(&unreferenced[i])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if collected {
+			result.Collected++
+		}
+	}
+
+	if s.pubsubService != nil {
+		resubscribed, err := s.resubscribeMissing()
+		result.Resubscribed = resubscribed
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// CollectChannels cleans up the given channels if nothing references them anymore and
+// returns how many were collected
+func (s *ChannelGCService) CollectChannels(channelIDs []uint) (int, error) {
+	if len(channelIDs) == 0 {
+		return 0, nil
+	}
+
+	var channels []models.Channel
+	if err := s.db.Where("id IN ?", channelIDs).Find(&channels).Error; err != nil {
+		return 0, fmt.Errorf("failed to load channels: %w", err)
+	}
+
+	collected := 0
+	var errs []error
+	for i := range channels {
+		ok, err := s.collect(&channels[i])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if ok {
+			collected++
+		}
+	}
+
+	return collected, errors.Join(errs...)
+}
+
+// collect unsubscribes from and soft-deletes a single channel with its videos, provided it is
+// still unreferenced. The unsubscribe happens first so a failure leaves the channel in place
+// to be retried instead of leaking an active subscription.
+func (s *ChannelGCService) collect(channel *models.Channel) (bool, error) {
+	referenced, err := s.isReferenced(s.db, channel.ID)
+	if err != nil {
+		return false, err
+	}
+	if referenced {
+		return false, nil
+	}
+
+	if s.pubsubService != nil {
+		if err := s.pubsubService.UnsubscribeFromChannel(channel.YoutubeID); err != nil {
+			return false, fmt.Errorf("failed to unsubscribe from channel %s: %w", channel.YoutubeID, err)
+		}
+	}
+
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return false, fmt.Errorf("failed to start transaction: %w", tx.Error)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// The channel may have been added to a watchlist since the first check
+	referenced, err = s.isReferenced(tx, channel.ID)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	if referenced {
+		tx.Rollback()
+		return false, nil
+	}
+
+	if err := tx.Where("channel_id = ?", channel.ID).Delete(&models.Video{}).Error; err != nil {
+		tx.Rollback()
+		return false, fmt.Errorf("failed to delete videos of channel %s: %w", channel.YoutubeID, err)
+	}
+
+	if err := tx.Delete(channel).Error; err != nil {
+		tx.Rollback()
+		return false, fmt.Errorf("failed to delete channel %s: %w", channel.YoutubeID, err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return false, err
+	}
+
+	log.Printf("Collected unreferenced channel %s", channel.YoutubeID)
+	return true, nil
+}
+
+// resubscribeMissing subscribes referenced channels whose subscription is missing, inactive or
+// past its lease
+func (s *ChannelGCService) resubscribeMissing() (int, error) {
+	var channels []models.Channel
+	if err := s.db.
+		Where("id IN (SELECT channel_id FROM watchlist_channels)").
+		Where(`youtube_id NOT IN (
+			SELECT channel_id FROM hub_subscriptions
+			WHERE deleted_at IS NULL AND is_active = ? AND (lease_seconds = 0 OR expires_at > ?)
+		)`, true, time.Now()).
+		Find(&channels).Error; err != nil {
+		return 0, fmt.Errorf("failed to find channels without subscriptions: %w", err)
+	}
+
+	resubscribed := 0
+	var errs []error
+	for _, channel := range channels {
+		if err := s.pubsubService.SubscribeToChannel(channel.YoutubeID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to resubscribe to channel %s: %w", channel.YoutubeID, err))
+			continue
+		}
+		resubscribed++
+	}
+
+	return resubscribed, errors.Join(errs...)
+}
+
+func (s *ChannelGCService) isReferenced(db *gorm.DB, channelID uint) (bool, error) {
+	var count int64
+	if err := db.Table("watchlist_channels").Where("channel_id = ?", channelID).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check channel usage: %w", err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"bytecast/internal/models"
+)
+
+var (
+	ErrRegistrationClosed = errors.New("registration is closed")
+	ErrInviteCodeRequired = errors.New("an invite code is required to register")
+	ErrInviteCodeInvalid  = errors.New("invite code is invalid, expired or used up")
+	ErrInviteCodeNotFound = errors.New("invite code not found")
+)
+
+// RegistrationMode controls who may create an account
+type RegistrationMode string
+
+const (
+	RegistrationOpen   RegistrationMode = "open"   // Anyone may register
+	RegistrationInvite RegistrationMode = "invite" // Registering takes an invite code from an admin
+	RegistrationClosed RegistrationMode = "closed" // Nobody may register; existing accounts still sign in
+)
+
+// IsValid reports whether m is a known registration mode
+func (m RegistrationMode) IsValid() bool {
+	return m == RegistrationOpen || m == RegistrationInvite || m == RegistrationClosed
+}
+
+const (
+	inviteCodeBytes     = 10
+	inviteDisplayPrefix = 4
+)
+
+// Codes are typed in by hand, so they use unpadded base32 and ignore case
+var inviteEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func normalizeInviteCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// SetRegistrationMode changes who may register. New services allow everyone.
+func (s *AuthService) SetRegistrationMode(mode RegistrationMode) {
+	s.registrationMode = mode
+}
+
+func (s *AuthService) RegistrationMode() RegistrationMode {
+	return s.registrationMode
+}
+
+/*
+ * checkRegistration enforces the registration mode for a new account. In
+ * invite mode it spends one use of the code within tx, so the use is given
+ * back if the registration is rolled back.
+ */
+func (s *AuthService) checkRegistration(tx *gorm.DB, inviteCode string) (*uint, error) {
+	switch s.registrationMode {
+	case RegistrationOpen:
+		return nil, nil
+	case RegistrationInvite:
+	default:
+		return nil, ErrRegistrationClosed
+	}
+
+	inviteCode = normalizeInviteCode(inviteCode)
+	if inviteCode == "" {
+		return nil, ErrInviteCodeRequired
+	}
+
+	var invite models.InviteCode
+	if err := tx.Where("code_hash = ?", hashToken(inviteCode)).First(&invite).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInviteCodeInvalid
+		}
+		return nil, err
+	}
+
+	// The conditions are checked in the update so concurrent registrations cannot overspend a code
+	result := tx.Model(&models.InviteCode{}).
+		Where("id = ? AND revoked_at IS NULL", invite.ID).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Where("max_uses = 0 OR uses < max_uses").
+		UpdateColumn("uses", gorm.Expr("uses + 1"))
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInviteCodeInvalid
+	}
+
+	return &invite.ID, nil
+}
+
+// CreateInviteCode issues a code usable maxUses times, or without limit when
+// maxUses is zero. The plaintext code is returned only here.
+func (s *AdminService) CreateInviteCode(adminID uint, note string, maxUses int, expiresAt *time.Time) (*models.InviteCode, string, error) {
+	b := make([]byte, inviteCodeBytes)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", err
+	}
+	raw := inviteEncoding.EncodeToString(b)
+
+	invite := &models.InviteCode{
+		Prefix:      raw[:inviteDisplayPrefix],
+		CodeHash:    hashToken(raw),
+		Note:        strings.TrimSpace(note),
+		MaxUses:     maxUses,
+		ExpiresAt:   expiresAt,
+		CreatedByID: adminID,
+	}
+	if err := s.db.Create(invite).Error; err != nil {
+		return nil, "", err
+	}
+
+	return invite, raw, nil
+}
+
+// ListInviteCodes returns every invite code, newest first, including revoked
+// and used up ones so admins can see how they were used
+func (s *AdminService) ListInviteCodes() ([]models.InviteCode, error) {
+	var invites []models.InviteCode
+	err := s.db.Order("created_at DESC, id DESC").Find(&invites).Error
+	return invites, err
+}
+
+// RevokeInviteCode stops the code from being used. Accounts already created
+// with it are not affected.
+func (s *AdminService) RevokeInviteCode(id uint) error {
+	result := s.db.Model(&models.InviteCode{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInviteCodeNotFound
+	}
+	return nil
+}
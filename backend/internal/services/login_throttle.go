@@ -0,0 +1,203 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"bytecast/internal/models"
+)
+
+// ErrTooManyAttempts is matched by every *LoginThrottledError
+var ErrTooManyAttempts = errors.New("too many failed login attempts")
+
+// LoginThrottledError is returned by LoginUser while the account or the
+// client IP is backing off or locked out.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("%s, retry in %s", ErrTooManyAttempts, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LoginThrottledError) Is(target error) bool {
+	return target == ErrTooManyAttempts
+}
+
+/*
+ * LoginThrottle configures brute-force protection for password logins.
+ *
+ * Failures are counted per account since its last successful login and per
+ * IP address regardless of successes, both within Window. An account is the
+ * user the identifier resolves to, or the identifier itself if it matches no
+ * user. Once a counter passes its free attempts every further attempt must
+ * wait BaseDelay, doubling per failure up to MaxDelay, measured from the
+ * latest failure. Reaching the lock threshold locks logins out for
+ * LockoutDuration.
+ */
+type LoginThrottle struct {
+	Window          time.Duration
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+	LockoutDuration time.Duration
+
+	AccountFreeAttempts int64
+	AccountLockAfter    int64
+	IPFreeAttempts      int64
+	IPLockAfter         int64
+}
+
+// DefaultLoginThrottle is the policy NewAuthService starts with
+var DefaultLoginThrottle = LoginThrottle{
+	Window:              time.Hour,
+	BaseDelay:           time.Second,
+	MaxDelay:            5 * time.Minute,
+	LockoutDuration:     15 * time.Minute,
+	AccountFreeAttempts: 3,
+	AccountLockAfter:    10,
+	IPFreeAttempts:      20,
+	IPLockAfter:         100,
+}
+
+// wait returns how long after the latest failure the next attempt is allowed
+func (t LoginThrottle) wait(failures, free, lockAfter int64) time.Duration {
+	switch {
+	case failures >= lockAfter:
+		return t.LockoutDuration
+	case failures < free:
+		return 0
+	}
+
+	delay := t.BaseDelay
+	for i := free; i < failures && delay < t.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > t.MaxDelay {
+		delay = t.MaxDelay
+	}
+	return delay
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// compareDummyHash spends as long as a real password check so the response
+// time doesn't reveal whether an account exists.
+func compareDummyHash(password string) {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("bytecast-dummy-password"), bcrypt.DefaultCost)
+	})
+	_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+}
+
+func normalizeIdentifier(identifier string) string {
+	return strings.ToLower(strings.TrimSpace(identifier))
+}
+
+// SetLoginThrottle replaces the brute-force protection policy
+func (s *AuthService) SetLoginThrottle(throttle LoginThrottle) {
+	s.throttle = throttle
+}
+
+/*
+ * checkLoginThrottle returns a *LoginThrottledError when the account or IP has
+ * to wait before trying again. userID is the account the identifier resolved
+ * to; failures against it count no matter whether the client typed the email
+ * or the username. Identifiers that match no account are counted on their own.
+ */
+func (s *AuthService) checkLoginThrottle(userID *uint, identifier, ipAddress string) error {
+	now := time.Now()
+	since := now.Add(-s.throttle.Window)
+
+	account := func(db *gorm.DB) *gorm.DB {
+		if userID != nil {
+			return db.Where("user_id = ?", *userID)
+		}
+		return db.Where("identifier = ? AND user_id IS NULL", identifier)
+	}
+
+	var lastSuccess models.LoginAttempt
+	err := s.db.Scopes(account).Where("succeeded = ? AND created_at > ?", true, since).
+		Order("created_at DESC").First(&lastSuccess).Error
+	switch {
+	case err == nil:
+		since = lastSuccess.CreatedAt
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return err
+	}
+
+	retryAt, err := s.failureRetryAt(account, since,
+		s.throttle.AccountFreeAttempts, s.throttle.AccountLockAfter)
+	if err != nil {
+		return err
+	}
+
+	if ipAddress != "" {
+		byIP := func(db *gorm.DB) *gorm.DB {
+			return db.Where("ip_address = ?", ipAddress)
+		}
+		ipRetryAt, err := s.failureRetryAt(byIP, now.Add(-s.throttle.Window),
+			s.throttle.IPFreeAttempts, s.throttle.IPLockAfter)
+		if err != nil {
+			return err
+		}
+		if ipRetryAt.After(retryAt) {
+			retryAt = ipRetryAt
+		}
+	}
+
+	if retryAt.After(now) {
+		return &LoginThrottledError{RetryAfter: retryAt.Sub(now)}
+	}
+	return nil
+}
+
+// failureRetryAt counts the failures selected by scope since the given time
+// and returns when the next attempt is allowed.
+func (s *AuthService) failureRetryAt(scope func(*gorm.DB) *gorm.DB, since time.Time, free, lockAfter int64) (time.Time, error) {
+	failed := s.db.Model(&models.LoginAttempt{}).
+		Scopes(scope).
+		Where("succeeded = ? AND created_at > ?", false, since).
+		Session(&gorm.Session{})
+
+	var failures int64
+	err := failed.Count(&failures).Error
+	if err != nil || failures == 0 {
+		return time.Time{}, err
+	}
+
+	wait := s.throttle.wait(failures, free, lockAfter)
+	if wait == 0 {
+		return time.Time{}, nil
+	}
+
+	var latest models.LoginAttempt
+	err = failed.Order("created_at DESC").First(&latest).Error
+	if err != nil {
+		return time.Time{}, err
+	}
+	return latest.CreatedAt.Add(wait), nil
+}
+
+func (s *AuthService) recordLoginAttempt(identifier string, userID *uint, ipAddress string, succeeded bool) error {
+	return s.db.Create(&models.LoginAttempt{
+		Identifier: identifier,
+		UserID:     userID,
+		IPAddress:  ipAddress,
+		Succeeded:  succeeded,
+	}).Error
+}
+
+// PurgeLoginAttempts deletes login attempts recorded before the given time
+func (s *AuthService) PurgeLoginAttempts(before time.Time) (int64, error) {
+	result := s.db.Where("created_at < ?", before).Delete(&models.LoginAttempt{})
+	return result.RowsAffected, result.Error
+}
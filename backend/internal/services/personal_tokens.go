@@ -0,0 +1,137 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"bytecast/internal/models"
+)
+
+var (
+	ErrPersonalTokenNotFound = errors.New("personal access token not found")
+	ErrPersonalTokenLimit    = errors.New("personal access token limit reached")
+	ErrInvalidTokenScope     = errors.New("invalid token scope")
+)
+
+const (
+	// PersonalTokenPrefix marks personal access tokens so they can be told
+	// apart from JWTs and recognised by secret scanners.
+	PersonalTokenPrefix = "bct_"
+
+	maxPersonalTokens  = 25
+	personalTokenBytes = 32
+	displayPrefixLen   = len(PersonalTokenPrefix) + 8
+
+	// Last use is tracked to the minute so busy scripts don't write on every request
+	lastUsedResolution = time.Minute
+)
+
+// CreatePersonalToken issues a token for the user. The plaintext token is
+// returned only here; a nil expiresAt means the token does not expire.
+func (s *AuthService) CreatePersonalToken(userID uint, name string, scope models.TokenScope, expiresAt *time.Time) (*models.PersonalAccessToken, string, error) {
+	if !scope.IsValid() {
+		return nil, "", ErrInvalidTokenScope
+	}
+
+	var count int64
+	if err := s.db.Model(&models.PersonalAccessToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Count(&count).Error; err != nil {
+		return nil, "", err
+	}
+	if count >= maxPersonalTokens {
+		return nil, "", ErrPersonalTokenLimit
+	}
+
+	b := make([]byte, personalTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", err
+	}
+	raw := PersonalTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	pat := &models.PersonalAccessToken{
+		UserID:    userID,
+		Name:      strings.TrimSpace(name),
+		Prefix:    raw[:displayPrefixLen],
+		TokenHash: hashToken(raw),
+		Scope:     scope,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.db.Create(pat).Error; err != nil {
+		return nil, "", err
+	}
+
+	return pat, raw, nil
+}
+
+// GetPersonalTokens lists the user's tokens that have not been revoked
+func (s *AuthService) GetPersonalTokens(userID uint) ([]models.PersonalAccessToken, error) {
+	var tokens []models.PersonalAccessToken
+	err := s.db.Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// RevokePersonalToken revokes one of the user's tokens
+func (s *AuthService) RevokePersonalToken(userID, tokenID uint) error {
+	result := s.db.Model(&models.PersonalAccessToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", tokenID, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPersonalTokenNotFound
+	}
+	return nil
+}
+
+// AuthenticatePersonalToken resolves a presented token to its user and scope,
+// and records when it was last used.
+func (s *AuthService) AuthenticatePersonalToken(raw string) (uint, string, error) {
+	if !strings.HasPrefix(raw, PersonalTokenPrefix) {
+		return 0, "", ErrTokenInvalid
+	}
+
+	var pat models.PersonalAccessToken
+	err := s.db.Where("token_hash = ?", hashToken(raw)).First(&pat).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, "", ErrTokenInvalid
+		}
+		return 0, "", err
+	}
+
+	now := time.Now()
+	if pat.RevokedAt != nil {
+		return 0, "", ErrTokenRevoked
+	}
+	if pat.ExpiresAt != nil && !now.Before(*pat.ExpiresAt) {
+		return 0, "", ErrTokenInvalid
+	}
+
+	var user models.User
+	if err := s.db.Select("id", "disabled_at").First(&user, pat.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, "", ErrTokenInvalid
+		}
+		return 0, "", err
+	}
+	if user.DisabledAt != nil {
+		return 0, "", ErrAccountDisabled
+	}
+
+	if pat.LastUsedAt == nil || now.Sub(*pat.LastUsedAt) >= lastUsedResolution {
+		if err := s.db.Model(&pat).Update("last_used_at", now).Error; err != nil {
+			return 0, "", err
+		}
+	}
+
+	return pat.UserID, string(pat.Scope), nil
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"bytecast/internal/models"
+)
+
+// PreferencesUpdate changes the non-nil fields. DefaultWatchlistID set to zero
+// clears the default watchlist.
+type PreferencesUpdate struct {
+	Timezone                   *string
+	DefaultWatchlistID         *uint
+	FeedSort                   *models.FeedSort
+	HideShorts                 *bool
+	Theme                      *models.Theme
+	Locale                     *string
+	NotifyNewVideos            *bool
+	NotifyWatchlistInvitations *bool
+}
+
+type PreferencesService struct {
+	db               *gorm.DB
+	watchlistService *WatchlistService
+}
+
+func NewPreferencesService(db *gorm.DB, watchlistService *WatchlistService) *PreferencesService {
+	return &PreferencesService{
+		db:               db,
+		watchlistService: watchlistService,
+	}
+}
+
+// GetPreferences returns the user's preferences, or the defaults if the user
+// never changed any
+func (s *PreferencesService) GetPreferences(userID uint) (*models.UserPreferences, error) {
+	prefs := models.DefaultUserPreferences(userID)
+	err := s.db.Where("user_id = ?", userID).Take(&prefs).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return &prefs, nil
+}
+
+// UpdatePreferences applies the update on top of the current preferences. The
+// values must already be validated; only the default watchlist is checked
+// here, since that depends on what the user can access.
+func (s *PreferencesService) UpdatePreferences(userID uint, update PreferencesUpdate) (*models.UserPreferences, error) {
+	prefs, err := s.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.DefaultWatchlistID != nil {
+		if *update.DefaultWatchlistID == 0 {
+			prefs.DefaultWatchlistID = nil
+		} else {
+			// Any watchlist the user can view will do, including shared ones
+			if _, err := s.watchlistService.authorize(s.db, *update.DefaultWatchlistID, userID, models.WatchlistRoleViewer); err != nil {
+				return nil, err
+			}
+			prefs.DefaultWatchlistID = update.DefaultWatchlistID
+		}
+	}
+	if update.Timezone != nil {
+		prefs.Timezone = *update.Timezone
+	}
+	if update.FeedSort != nil {
+		prefs.FeedSort = *update.FeedSort
+	}
+	if update.HideShorts != nil {
+		prefs.HideShorts = *update.HideShorts
+	}
+	if update.Theme != nil {
+		prefs.Theme = *update.Theme
+	}
+	if update.Locale != nil {
+		prefs.Locale = *update.Locale
+	}
+	if update.NotifyNewVideos != nil {
+		prefs.Notifications.NewVideos = *update.NotifyNewVideos
+	}
+	if update.NotifyWatchlistInvitations != nil {
+		prefs.Notifications.WatchlistInvitations = *update.NotifyWatchlistInvitations
+	}
+
+	if err := s.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(prefs).Error; err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
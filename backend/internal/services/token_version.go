@@ -0,0 +1,104 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"bytecast/internal/models"
+)
+
+type tokenVersionEntry struct {
+	version   uint
+	expiresAt time.Time
+}
+
+/*
+ * TokenVersionCache caches users' token versions so the auth middleware does
+ * not hit the database on every request. Bumps made through this process
+ * invalidate the entry immediately; other instances pick them up once their
+ * entry expires, which bounds how long a revoked access token stays usable.
+ *
+ * Expired entries are swept out at most once per TTL when a new entry is
+ * stored, so the map only holds users seen in roughly the last two TTLs.
+ */
+type TokenVersionCache struct {
+	db        *gorm.DB
+	ttl       time.Duration
+	mu        sync.Mutex
+	entries   map[uint]tokenVersionEntry
+	lastSweep time.Time
+}
+
+func NewTokenVersionCache(db *gorm.DB, ttl time.Duration) *TokenVersionCache {
+	return &TokenVersionCache{
+		db:      db,
+		ttl:     ttl,
+		entries: make(map[uint]tokenVersionEntry),
+	}
+}
+
+// Get returns the user's current token version, loading it on a cache miss
+func (c *TokenVersionCache) Get(userID uint) (uint, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[userID]
+	c.mu.Unlock()
+
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.version, nil
+	}
+
+	var user models.User
+	if err := c.db.Select("id", "token_version").First(&user, userID).Error; err != nil {
+		if ok {
+			c.Invalidate(userID)
+		}
+		return 0, err
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	c.entries[userID] = tokenVersionEntry{
+		version:   user.TokenVersion,
+		expiresAt: now.Add(c.ttl),
+	}
+	if now.Sub(c.lastSweep) >= c.ttl {
+		c.sweep(now)
+	}
+	c.mu.Unlock()
+
+	return user.TokenVersion, nil
+}
+
+// Len returns the number of cached entries, expired ones included
+func (c *TokenVersionCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// sweep deletes expired entries; the caller holds c.mu
+func (c *TokenVersionCache) sweep(now time.Time) {
+	for userID, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, userID)
+		}
+	}
+	c.lastSweep = now
+}
+
+// Invalidate drops the cached version so the next lookup reads the database
+func (c *TokenVersionCache) Invalidate(userID uint) {
+	c.mu.Lock()
+	delete(c.entries, userID)
+	c.mu.Unlock()
+}
+
+// bumpTokenVersion increments the user's token version, invalidating every
+// access token issued before the bump
+func bumpTokenVersion(db *gorm.DB, userID uint) error {
+	return db.Model(&models.User{}).
+		Where("id = ?", userID).
+		UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error
+}
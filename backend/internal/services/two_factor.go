@@ -0,0 +1,184 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"bytecast/internal/models"
+	"bytecast/internal/token"
+	"bytecast/internal/totp"
+)
+
+var (
+	ErrTwoFactorRequired     = errors.New("two-factor authentication required")
+	ErrTwoFactorEnabled      = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled   = errors.New("two-factor authentication is not enabled")
+	ErrTOTPEnrollmentMissing = errors.New("TOTP enrollment has not been started")
+	ErrInvalidTwoFactorCode  = errors.New("invalid two-factor code")
+)
+
+const (
+	twoFactorChallengeTTL = 5 * time.Minute
+	totpSkew              = 1 // Time steps of clock drift accepted either way
+	recoveryCodeCount     = 10
+	recoveryCodeBytes     = 10 // 80 bits, 16 base32 characters
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TwoFactorRequiredError is returned by LoginUser when the password was
+// correct but the account has two-factor authentication enabled. The
+// challenge token is exchanged together with a code by CompleteTwoFactorLogin.
+type TwoFactorRequiredError struct {
+	ChallengeToken string
+	ExpiresAt      time.Time
+}
+
+func (e *TwoFactorRequiredError) Error() string {
+	return ErrTwoFactorRequired.Error()
+}
+
+func (e *TwoFactorRequiredError) Is(target error) bool {
+	return target == ErrTwoFactorRequired
+}
+
+func (s *AuthService) twoFactorChallenge(user *models.User) error {
+	if user.DisabledAt != nil {
+		return ErrAccountDisabled
+	}
+
+	challenge, claims, err := s.tokens.Issue(user.ID, token.TypeTwoFactor, twoFactorChallengeTTL, token.Claims{
+		TokenVersion: user.TokenVersion,
+	})
+	if err != nil {
+		return err
+	}
+
+	return &TwoFactorRequiredError{ChallengeToken: challenge, ExpiresAt: claims.ExpiresAt.Time}
+}
+
+/*
+ * CompleteTwoFactorLogin finishes a login that LoginUser answered with a
+ * challenge. The code is either a current TOTP code or an unused recovery
+ * code. Attempts are throttled and recorded against the account, together
+ * with password failures. The signed in user is returned alongside the
+ * tokens, and also with ErrInvalidTwoFactorCode so the failed attempt can be
+ * attributed.
+ */
+func (s *AuthService) CompleteTwoFactorLogin(challenge, code string, client ClientInfo) (*TokenPair, time.Time, *models.User, error) {
+	claims, err := s.tokens.Parse(challenge, token.TypeTwoFactor)
+	if err != nil {
+		return nil, time.Time{}, nil, ErrTokenInvalid
+	}
+	userID, err := claims.UserID()
+	if err != nil {
+		return nil, time.Time{}, nil, ErrTokenInvalid
+	}
+
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, time.Time{}, nil, ErrTokenInvalid
+		}
+		return nil, time.Time{}, nil, err
+	}
+
+	// A password change or sign-out everywhere since the challenge was issued voids it
+	if user.TOTPEnabledAt == nil || user.TokenVersion != claims.TokenVersion {
+		return nil, time.Time{}, nil, ErrTokenInvalid
+	}
+
+	key := normalizeIdentifier(user.Username)
+	if err := s.checkLoginThrottle(&user.ID, key, client.IPAddress); err != nil {
+		return nil, time.Time{}, nil, err
+	}
+
+	ok, err := verifySecondFactor(s.db, &user, code)
+	if err != nil {
+		return nil, time.Time{}, nil, err
+	}
+	if err := s.recordLoginAttempt(key, &user.ID, client.IPAddress, ok); err != nil {
+		return nil, time.Time{}, nil, err
+	}
+	if !ok {
+		return nil, time.Time{}, &user, ErrInvalidTwoFactorCode
+	}
+
+	tokens, exp, err := s.startSession(&user, client)
+	if err != nil {
+		return nil, time.Time{}, nil, err
+	}
+	return tokens, exp, &user, nil
+}
+
+// verifySecondFactor accepts a TOTP code newer than the last one used, or
+// consumes an unused recovery code.
+func verifySecondFactor(db *gorm.DB, user *models.User, code string) (bool, error) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+
+	if isTOTPCode(code) {
+		counter, ok := totp.Validate(user.TOTPSecret, code, time.Now(), totpSkew)
+		if !ok || counter <= user.TOTPLastCounter {
+			return false, nil
+		}
+
+		// Conditional so two concurrent logins cannot both use the same code
+		result := db.Model(&models.User{}).
+			Where("id = ? AND totp_last_counter < ?", user.ID, counter).
+			Update("totp_last_counter", counter)
+		return result.RowsAffected == 1, result.Error
+	}
+
+	result := db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, hashToken(normalizeRecoveryCode(code))).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != totp.Digits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(code, "-", ""))
+}
+
+// replaceRecoveryCodes discards the user's recovery codes and returns a new
+// set formatted as xxxx-xxxx-xxxx-xxxx.
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	rows := make([]models.RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		raw := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))
+
+		codes[i] = fmt.Sprintf("%s-%s-%s-%s", raw[0:4], raw[4:8], raw[8:12], raw[12:16])
+		rows[i] = models.RecoveryCode{UserID: userID, CodeHash: hashToken(raw)}
+	}
+
+	if err := tx.Create(&rows).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
@@ -1,10 +1,13 @@
 package services
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -17,6 +20,7 @@ var (
 	ErrChannelNotFound   = errors.New("channel not found")
 	ErrNotAuthorized     = errors.New("not authorized to access this watchlist")
 	ErrInvalidYouTubeID  = errors.New("invalid YouTube channel ID or URL")
+	ErrShareLinkNotFound = errors.New("shared watchlist not found")
 )
 
 type YouTubeServiceInterface interface {
@@ -28,11 +32,16 @@ type PubSubServiceInterface interface {
 	UnsubscribeFromChannel(channelID string) error
 }
 
+type ChannelCollector interface {
+	CollectChannels(channelIDs []uint) (int, error)
+}
+
 type WatchlistService struct {
 	db             *gorm.DB
 	config         *configs.Config
 	youtubeService YouTubeServiceInterface
 	pubsubService  PubSubServiceInterface
+	channelGC      ChannelCollector
 }
 
 func NewWatchlistService(db *gorm.DB, config *configs.Config, youtubeService YouTubeServiceInterface) *WatchlistService {
@@ -74,28 +83,39 @@ func (s *WatchlistService) CreateWatchlist(userID uint, name, description, color
 }
 
 func (s *WatchlistService) GetWatchlist(watchlistID, userID uint) (*models.Watchlist, error) {
-	var watchlist models.Watchlist
-	if err := s.db.Preload("Channels").Where("id = ? AND user_id = ?", watchlistID, userID).First(&watchlist).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrWatchlistNotFound
-		}
-		return nil, err
-	}
-
-	return &watchlist, nil
+	return s.authorize(s.db.Preload("Channels"), watchlistID, userID, models.WatchlistRoleViewer)
 }
 
+// GetUserWatchlists returns the watchlists the user owns followed by those shared with them
 func (s *WatchlistService) GetUserWatchlists(userID uint) ([]models.Watchlist, error) {
 	var watchlists []models.Watchlist
 	if err := s.db.Where("user_id = ?", userID).Find(&watchlists).Error; err != nil {
 		return nil, err
 	}
 
+	for i := range watchlists {
+		watchlists[i].Role = models.WatchlistRoleOwner
+	}
+
+	var memberships []models.WatchlistMember
+	if err := s.db.Preload("Watchlist").Where("user_id = ?", userID).Find(&memberships).Error; err != nil {
+		return nil, err
+	}
+
+	for _, membership := range memberships {
+		// Preload leaves a zero value when the watchlist has been deleted
+		if membership.Watchlist.ID == 0 {
+			continue
+		}
+		membership.Watchlist.Role = membership.Role
+		watchlists = append(watchlists, membership.Watchlist)
+	}
+
 	return watchlists, nil
 }
 
 func (s *WatchlistService) UpdateWatchlist(watchlistID, userID uint, name, description, color string) (*models.Watchlist, error) {
-	watchlist, err := s.GetWatchlist(watchlistID, userID)
+	watchlist, err := s.authorize(s.db, watchlistID, userID, models.WatchlistRoleEditor)
 	if err != nil {
 		return nil, err
 	}
@@ -112,16 +132,12 @@ func (s *WatchlistService) UpdateWatchlist(watchlistID, userID uint, name, descr
 }
 
 func (s *WatchlistService) DeleteWatchlist(watchlistID, userID uint) error {
-	result := s.db.Where("id = ? AND user_id = ?", watchlistID, userID).Delete(&models.Watchlist{})
-	if result.Error != nil {
-		return result.Error
-	}
-
-	if result.RowsAffected == 0 {
-		return ErrWatchlistNotFound
+	watchlist, err := s.authorize(s.db, watchlistID, userID, models.WatchlistRoleOwner)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return s.db.Delete(watchlist).Error
 }
 
 func (s *WatchlistService) AddChannelToWatchlist(watchlistID, userID uint, channelID string) error {
@@ -136,13 +152,8 @@ func (s *WatchlistService) AddChannelToWatchlist(watchlistID, userID uint, chann
 		}
 	}()
 
-	// Verify watchlist belongs to user
-	var watchlist models.Watchlist
-	if err := tx.Where("id = ? AND user_id = ?", watchlistID, userID).First(&watchlist).Error; err != nil {
+	if _, err := s.authorize(tx, watchlistID, userID, models.WatchlistRoleEditor); err != nil {
 		tx.Rollback()
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrWatchlistNotFound
-		}
 		return err
 	}
 
@@ -253,12 +264,8 @@ func (s *WatchlistService) RemoveChannelFromWatchlist(watchlistID, userID uint,
 		}
 	}()
 
-	var watchlist models.Watchlist
-	if err := tx.Where("id = ? AND user_id = ?", watchlistID, userID).First(&watchlist).Error; err != nil {
+	if _, err := s.authorize(tx, watchlistID, userID, models.WatchlistRoleEditor); err != nil {
 		tx.Rollback()
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrWatchlistNotFound
-		}
 		return err
 	}
 
@@ -295,55 +302,27 @@ func (s *WatchlistService) RemoveChannelFromWatchlist(watchlistID, userID uint,
 		return fmt.Errorf("failed to remove channel from watchlist: %w", err)
 	}
 	
-	var usageCount int64
-	if err := tx.Model(&models.Watchlist{}).
-		Joins("JOIN watchlist_channels ON watchlist_channels.watchlist_id = watchlists.id").
-		Where("watchlist_channels.channel_id = ?", channel.ID).
-		Count(&usageCount).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to check channel usage: %w", err)
-	}
-	
-	needsCleanup := usageCount == 0
-	
 	if err := tx.Commit().Error; err != nil {
 		return err
 	}
 	
-	if needsCleanup {
-		if s.pubsubService != nil {
-			if err := s.pubsubService.UnsubscribeFromChannel(extractedID); err != nil {
-				log.Printf("Warning: Failed to unsubscribe from YouTube PubSubHubbub for channel %s: %v", extractedID, err)
-			}
-		}
-		
-		cleanupTx := s.db.Begin()
-		if cleanupTx.Error != nil {
-			log.Printf("Warning: Failed to start cleanup transaction: %v", cleanupTx.Error)
-			return nil
-		}
-		
-		// Soft-delete videos related to this channel
-		if err := cleanupTx.Where("channel_id = ?", channel.ID).Delete(&models.Video{}).Error; err != nil {
-			cleanupTx.Rollback()
-			log.Printf("Warning: Failed to delete channel videos: %v", err)
-			return nil
-		}
-		
-		if err := cleanupTx.Delete(&channel).Error; err != nil {
-			cleanupTx.Rollback()
-			log.Printf("Warning: Failed to delete channel: %v", err)
-			return nil
-		}
-		
-		if err := cleanupTx.Commit().Error; err != nil {
-			log.Printf("Warning: Failed to commit cleanup transaction: %v", err)
-		}
-	}
+	s.collectChannels([]uint{channel.ID})
 	
 	return nil
 }
 
+// collectChannels asks the channel GC to clean up channels that may have lost their last
+// reference. Failures are only logged since the periodic GC pass retries them.
+func (s *WatchlistService) collectChannels(channelIDs []uint) {
+	if s.channelGC == nil {
+		return
+	}
+
+	if _, err := s.channelGC.CollectChannels(channelIDs); err != nil {
+		log.Printf("Warning: Channel cleanup failed, deferring to the next GC pass: %v", err)
+	}
+}
+
 func (s *WatchlistService) GetChannelsInWatchlist(watchlistID, userID uint) ([]models.Channel, error) {
 	watchlist, err := s.GetWatchlist(watchlistID, userID)
 	if err != nil {
@@ -358,6 +337,192 @@ func (s *WatchlistService) GetChannelsInWatchlist(watchlistID, userID uint) ([]m
 	return channels, nil
 }
 
+// authorize loads the watchlist and checks that the user holds at least the required role.
+// Users without any access get ErrWatchlistNotFound so watchlist IDs are not leaked.
+func (s *WatchlistService) authorize(db *gorm.DB, watchlistID, userID uint, required models.WatchlistRole) (*models.Watchlist, error) {
+	var watchlist models.Watchlist
+	if err := db.Where("id = ?", watchlistID).First(&watchlist).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWatchlistNotFound
+		}
+		return nil, err
+	}
+
+	role, err := s.roleFor(db, &watchlist, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if role == "" {
+		return nil, ErrWatchlistNotFound
+	}
+
+	if !role.Allows(required) {
+		return nil, ErrNotAuthorized
+	}
+
+	watchlist.Role = role
+	return &watchlist, nil
+}
+
+// roleFor returns the user's role on the watchlist, or an empty role if they have no access
+func (s *WatchlistService) roleFor(db *gorm.DB, watchlist *models.Watchlist, userID uint) (models.WatchlistRole, error) {
+	if watchlist.UserID == userID {
+		return models.WatchlistRoleOwner, nil
+	}
+
+	var member models.WatchlistMember
+	if err := db.Session(&gorm.Session{NewDB: true}).
+		Where("watchlist_id = ? AND user_id = ?", watchlist.ID, userID).
+		First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return member.Role, nil
+}
+
+// CreateShareLink generates a new public slug for the watchlist, replacing any existing one
+func (s *WatchlistService) CreateShareLink(watchlistID, userID uint) (*models.Watchlist, error) {
+	watchlist, err := s.authorize(s.db, watchlistID, userID, models.WatchlistRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	slug, err := generateShareSlug()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate share slug: %w", err)
+	}
+
+	now := time.Now()
+	if err := s.db.Model(watchlist).Updates(map[string]interface{}{
+		"share_slug": slug,
+		"shared_at":  now,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	watchlist.ShareSlug = &slug
+	watchlist.SharedAt = &now
+
+	return watchlist, nil
+}
+
+// RevokeShareLink disables the public link, any previously handed out slug stops resolving
+func (s *WatchlistService) RevokeShareLink(watchlistID, userID uint) error {
+	watchlist, err := s.authorize(s.db, watchlistID, userID, models.WatchlistRoleOwner)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Model(watchlist).Updates(map[string]interface{}{
+		"share_slug": nil,
+		"shared_at":  nil,
+	}).Error
+}
+
+// GetSharedWatchlist looks up a watchlist by its public slug without any ownership check
+func (s *WatchlistService) GetSharedWatchlist(slug string) (*models.Watchlist, error) {
+	if slug == "" {
+		return nil, ErrShareLinkNotFound
+	}
+
+	var watchlist models.Watchlist
+	if err := s.db.Preload("Channels").Where("share_slug = ?", slug).First(&watchlist).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShareLinkNotFound
+		}
+		return nil, err
+	}
+
+	return &watchlist, nil
+}
+
+// CloneSharedWatchlist copies a shared watchlist and its channels into the caller's watchlists.
+// The channels are already referenced by the source so no new WebSub subscriptions are needed.
+func (s *WatchlistService) CloneSharedWatchlist(slug string, userID uint) (*models.Watchlist, error) {
+	if userID == 0 {
+		return nil, errors.New("user ID is required")
+	}
+
+	source, err := s.GetSharedWatchlist(slug)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", tx.Error)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	clone := models.Watchlist{
+		UserID:      userID,
+		Name:        source.Name,
+		Description: source.Description,
+		Color:       source.Color,
+	}
+
+	if err := tx.Create(&clone).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := copyWatchlistItems(tx, source.ID, clone.ID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return &clone, nil
+}
+
+// copyWatchlistItems copies channel and video associations from one watchlist to another,
+// skipping rows the target already has
+func copyWatchlistItems(tx *gorm.DB, sourceID, targetID uint) error {
+	if err := tx.Exec(`
+		INSERT INTO watchlist_channels (watchlist_id, channel_id)
+		SELECT ?, channel_id FROM watchlist_channels
+		WHERE watchlist_id = ? AND channel_id NOT IN (
+			SELECT channel_id FROM watchlist_channels WHERE watchlist_id = ?
+		)
+	`, targetID, sourceID, targetID).Error; err != nil {
+		return fmt.Errorf("failed to copy channels: %w", err)
+	}
+
+	if err := tx.Exec(`
+		INSERT INTO watchlist_videos (watchlist_id, video_id)
+		SELECT ?, video_id FROM watchlist_videos
+		WHERE watchlist_id = ? AND video_id NOT IN (
+			SELECT video_id FROM watchlist_videos WHERE watchlist_id = ?
+		)
+	`, targetID, sourceID, targetID).Error; err != nil {
+		return fmt.Errorf("failed to copy videos: %w", err)
+	}
+
+	return nil
+}
+
+// generateShareSlug returns a random URL-safe slug for public watchlist links
+func generateShareSlug() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
 // SetYouTubeService sets the YouTube service (used for testing)
 func (s *WatchlistService) SetYouTubeService(youtubeService YouTubeServiceInterface) {
 	s.youtubeService = youtubeService
@@ -366,3 +531,7 @@ func (s *WatchlistService) SetYouTubeService(youtubeService YouTubeServiceInterf
 func (s *WatchlistService) SetPubSubService(pubsubService PubSubServiceInterface) {
 	s.pubsubService = pubsubService
 }
+
+func (s *WatchlistService) SetChannelGC(channelGC ChannelCollector) {
+	s.channelGC = channelGC
+}
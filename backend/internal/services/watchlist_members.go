@@ -0,0 +1,210 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"bytecast/internal/models"
+)
+
+var (
+	ErrInviteeNotFound      = errors.New("no user found with that username or email")
+	ErrInvalidInvitation    = errors.New("cannot invite this user to the watchlist")
+	ErrAlreadyMember        = errors.New("user is already a member of this watchlist")
+	ErrInvitationNotFound   = errors.New("invitation not found")
+	ErrMemberNotFound       = errors.New("member not found")
+	ErrInvalidWatchlistRole = errors.New("invalid watchlist role")
+)
+
+// GetMembers lists everyone with access to the watchlist, the owner first
+func (s *WatchlistService) GetMembers(watchlistID, userID uint) ([]models.WatchlistMember, error) {
+	watchlist, err := s.authorize(s.db, watchlistID, userID, models.WatchlistRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	var owner models.User
+	if err := s.db.First(&owner, watchlist.UserID).Error; err != nil {
+		return nil, err
+	}
+
+	var members []models.WatchlistMember
+	if err := s.db.Preload("User").Where("watchlist_id = ?", watchlistID).Order("created_at").Find(&members).Error; err != nil {
+		return nil, err
+	}
+
+	return append([]models.WatchlistMember{{
+		WatchlistID: watchlist.ID,
+		UserID:      owner.ID,
+		Role:        models.WatchlistRoleOwner,
+		User:        owner,
+	}}, members...), nil
+}
+
+// InviteMember invites a user, looked up by username or email, to join the watchlist.
+// Re-inviting a user with a pending invitation updates the offered role.
+func (s *WatchlistService) InviteMember(watchlistID, ownerID uint, identifier string, role models.WatchlistRole) (*models.WatchlistInvitation, error) {
+	if !role.IsValid() {
+		return nil, ErrInvalidWatchlistRole
+	}
+
+	watchlist, err := s.authorize(s.db, watchlistID, ownerID, models.WatchlistRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	var invitee models.User
+	if err := s.db.Where("email = ? OR username = ?", identifier, identifier).First(&invitee).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInviteeNotFound
+		}
+		return nil, err
+	}
+
+	if invitee.ID == watchlist.UserID {
+		return nil, ErrInvalidInvitation
+	}
+
+	existingRole, err := s.roleFor(s.db, watchlist, invitee.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existingRole != "" {
+		return nil, ErrAlreadyMember
+	}
+
+	var invitation models.WatchlistInvitation
+	err = s.db.Where("watchlist_id = ? AND invitee_id = ? AND status = ?", watchlistID, invitee.ID, models.InvitationPending).
+		First(&invitation).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		invitation = models.WatchlistInvitation{
+			WatchlistID: watchlistID,
+			InviterID:   ownerID,
+			InviteeID:   invitee.ID,
+			Role:        role,
+			Status:      models.InvitationPending,
+		}
+		if err := s.db.Create(&invitation).Error; err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	} else {
+		invitation.Role = role
+		if err := s.db.Save(&invitation).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	invitation.Invitee = invitee
+	return &invitation, nil
+}
+
+// GetPendingInvitations returns the invitations waiting for the user's response
+func (s *WatchlistService) GetPendingInvitations(userID uint) ([]models.WatchlistInvitation, error) {
+	var invitations []models.WatchlistInvitation
+	if err := s.db.Preload("Watchlist").Preload("Inviter").
+		Where("invitee_id = ? AND status = ?", userID, models.InvitationPending).
+		Order("created_at DESC").
+		Find(&invitations).Error; err != nil {
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+// RespondToInvitation accepts or declines a pending invitation addressed to the user
+func (s *WatchlistService) RespondToInvitation(invitationID, userID uint, accept bool) error {
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return fmt.Errorf("failed to start transaction: %w", tx.Error)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var invitation models.WatchlistInvitation
+	if err := tx.Where("id = ? AND invitee_id = ? AND status = ?", invitationID, userID, models.InvitationPending).
+		First(&invitation).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvitationNotFound
+		}
+		return err
+	}
+
+	invitation.Status = models.InvitationDeclined
+	if accept {
+		invitation.Status = models.InvitationAccepted
+
+		member := models.WatchlistMember{
+			WatchlistID: invitation.WatchlistID,
+			UserID:      userID,
+			Role:        invitation.Role,
+		}
+		if err := tx.Create(&member).Error; err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to add member: %w", err)
+		}
+	}
+
+	if err := tx.Save(&invitation).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// UpdateMemberRole changes the role of an existing member, only the owner may do this
+func (s *WatchlistService) UpdateMemberRole(watchlistID, ownerID, memberUserID uint, role models.WatchlistRole) error {
+	if !role.IsValid() {
+		return ErrInvalidWatchlistRole
+	}
+
+	if _, err := s.authorize(s.db, watchlistID, ownerID, models.WatchlistRoleOwner); err != nil {
+		return err
+	}
+
+	result := s.db.Model(&models.WatchlistMember{}).
+		Where("watchlist_id = ? AND user_id = ?", watchlistID, memberUserID).
+		Update("role", role)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrMemberNotFound
+	}
+
+	return nil
+}
+
+// RemoveMember revokes a member's access. The owner can remove anyone, members can only remove themselves.
+func (s *WatchlistService) RemoveMember(watchlistID, userID, memberUserID uint) error {
+	required := models.WatchlistRoleOwner
+	if userID == memberUserID {
+		required = models.WatchlistRoleViewer
+	}
+
+	if _, err := s.authorize(s.db, watchlistID, userID, required); err != nil {
+		return err
+	}
+
+	result := s.db.Where("watchlist_id = ? AND user_id = ?", watchlistID, memberUserID).Delete(&models.WatchlistMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrMemberNotFound
+	}
+
+	return nil
+}
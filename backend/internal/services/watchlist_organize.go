@@ -0,0 +1,196 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"bytecast/internal/models"
+)
+
+var ErrSameWatchlist = errors.New("source and target watchlist must be different")
+
+/*
+ * The operations below only rewrite watchlist_channels and watchlist_videos rows
+ * inside a single transaction. Every channel involved stays referenced by at
+ * least one watchlist throughout, so WebSub subscriptions are never touched and
+ * no channel or video is ever cleaned up.
+ */
+
+// DuplicateWatchlist copies a watchlist the user can read, including its channels and videos,
+// into a new watchlist owned by the user
+func (s *WatchlistService) DuplicateWatchlist(watchlistID, userID uint, name string) (*models.Watchlist, error) {
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", tx.Error)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	source, err := s.authorize(tx, watchlistID, userID, models.WatchlistRoleViewer)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if name == "" {
+		name = source.Name + " (copy)"
+	}
+
+	duplicate := models.Watchlist{
+		UserID:      userID,
+		Name:        name,
+		Description: source.Description,
+		Color:       source.Color,
+	}
+
+	if err := tx.Create(&duplicate).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := copyWatchlistItems(tx, source.ID, duplicate.ID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	duplicate.Role = models.WatchlistRoleOwner
+	return &duplicate, nil
+}
+
+// MergeWatchlists moves every channel and video of the source into the target and then deletes
+// the source. The user must own the source and be able to edit the target.
+func (s *WatchlistService) MergeWatchlists(sourceID, targetID, userID uint) (*models.Watchlist, error) {
+	if sourceID == targetID {
+		return nil, ErrSameWatchlist
+	}
+
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", tx.Error)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	source, err := s.authorize(tx, sourceID, userID, models.WatchlistRoleOwner)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	target, err := s.authorize(tx, targetID, userID, models.WatchlistRoleEditor)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := copyWatchlistItems(tx, source.ID, target.ID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Delete(source).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to delete merged watchlist: %w", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
+
+// MoveChannel moves a channel and its videos from one watchlist to another the user can edit
+func (s *WatchlistService) MoveChannel(sourceID, targetID, userID uint, channelID string) error {
+	if sourceID == targetID {
+		return ErrSameWatchlist
+	}
+
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return fmt.Errorf("failed to start transaction: %w", tx.Error)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if _, err := s.authorize(tx, sourceID, userID, models.WatchlistRoleEditor); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := s.authorize(tx, targetID, userID, models.WatchlistRoleEditor); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var channel models.Channel
+	if err := tx.Joins("JOIN watchlist_channels ON watchlist_channels.channel_id = channels.id").
+		Where("watchlist_channels.watchlist_id = ? AND channels.youtube_id = ?", sourceID, channelID).
+		First(&channel).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrChannelNotFound
+		}
+		return err
+	}
+
+	// Add to the target before removing from the source so the channel is never unreferenced
+	if err := tx.Exec(`
+		INSERT INTO watchlist_channels (watchlist_id, channel_id)
+		SELECT ?, ? WHERE NOT EXISTS (
+			SELECT 1 FROM watchlist_channels WHERE watchlist_id = ? AND channel_id = ?
+		)
+	`, targetID, channel.ID, targetID, channel.ID).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to add channel to target watchlist: %w", err)
+	}
+
+	if err := tx.Exec(`
+		INSERT INTO watchlist_videos (watchlist_id, video_id)
+		SELECT ?, video_id FROM watchlist_videos
+		WHERE watchlist_id = ? AND video_id IN (
+			SELECT id FROM youtube_videos WHERE channel_id = ?
+		) AND video_id NOT IN (
+			SELECT video_id FROM watchlist_videos WHERE watchlist_id = ?
+		)
+	`, targetID, sourceID, channel.ID, targetID).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to move videos to target watchlist: %w", err)
+	}
+
+	if err := tx.Exec(`
+		DELETE FROM watchlist_videos
+		WHERE watchlist_id = ? AND video_id IN (
+			SELECT id FROM youtube_videos WHERE channel_id = ?
+		)
+	`, sourceID, channel.ID).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to remove videos from source watchlist: %w", err)
+	}
+
+	if err := tx.Exec("DELETE FROM watchlist_channels WHERE watchlist_id = ? AND channel_id = ?",
+		sourceID, channel.ID).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to remove channel from source watchlist: %w", err)
+	}
+
+	return tx.Commit().Error
+}
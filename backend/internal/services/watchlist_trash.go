@@ -0,0 +1,132 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"bytecast/internal/models"
+)
+
+// GetTrashedWatchlists returns the user's soft-deleted watchlists, most recently deleted first
+func (s *WatchlistService) GetTrashedWatchlists(userID uint) ([]models.Watchlist, error) {
+	var watchlists []models.Watchlist
+	if err := s.db.Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at DESC").
+		Find(&watchlists).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range watchlists {
+		watchlists[i].Role = models.WatchlistRoleOwner
+	}
+
+	return watchlists, nil
+}
+
+// RestoreWatchlist brings a trashed watchlist back, together with its channels, videos and members
+func (s *WatchlistService) RestoreWatchlist(watchlistID, userID uint) (*models.Watchlist, error) {
+	var watchlist models.Watchlist
+	if err := s.db.Unscoped().
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", watchlistID, userID).
+		First(&watchlist).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWatchlistNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.db.Unscoped().Model(&watchlist).Update("deleted_at", nil).Error; err != nil {
+		return nil, err
+	}
+
+	watchlist.DeletedAt = gorm.DeletedAt{}
+	watchlist.Role = models.WatchlistRoleOwner
+	return &watchlist, nil
+}
+
+// PurgeTrashedWatchlists hard-deletes watchlists that have been in the trash since before the
+// cutoff, then hands the channels they referenced to the channel GC. It returns the number of
+// purged watchlists.
+func (s *WatchlistService) PurgeTrashedWatchlists(cutoff time.Time) (int, error) {
+	var watchlists []models.Watchlist
+	if err := s.db.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Find(&watchlists).Error; err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, watchlist := range watchlists {
+		channelIDs, err := s.purgeWatchlist(watchlist.ID)
+		if err != nil {
+			log.Printf("Warning: Failed to purge watchlist %d: %v", watchlist.ID, err)
+			continue
+		}
+		purged++
+
+		s.collectChannels(channelIDs)
+	}
+
+	return purged, nil
+}
+
+// purgeWatchlist permanently removes a watchlist and its association rows, returning the IDs
+// of the channels it referenced so the caller can clean up the ones left unused
+func (s *WatchlistService) purgeWatchlist(watchlistID uint) ([]uint, error) {
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", tx.Error)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	channelIDs, err := deleteWatchlistRows(tx, watchlistID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return channelIDs, nil
+}
+
+// deleteWatchlistRows permanently deletes a watchlist and its association rows within tx and
+// returns the IDs of the channels it referenced
+func deleteWatchlistRows(tx *gorm.DB, watchlistID uint) ([]uint, error) {
+	var channelIDs []uint
+	if err := tx.Table("watchlist_channels").
+		Where("watchlist_id = ?", watchlistID).
+		Pluck("channel_id", &channelIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load watchlist channels: %w", err)
+	}
+
+	for _, table := range []string{"watchlist_videos", "watchlist_channels", "watchlist_members", "watchlist_invitations"} {
+		if err := tx.Exec("DELETE FROM "+table+" WHERE watchlist_id = ?", watchlistID).Error; err != nil {
+			return nil, fmt.Errorf("failed to delete %s rows: %w", table, err)
+		}
+	}
+
+	if err := tx.Model(&models.UserPreferences{}).
+		Where("default_watchlist_id = ?", watchlistID).
+		Update("default_watchlist_id", nil).Error; err != nil {
+		return nil, fmt.Errorf("failed to clear default watchlist: %w", err)
+	}
+
+	if err := tx.Unscoped().Delete(&models.Watchlist{}, watchlistID).Error; err != nil {
+		return nil, fmt.Errorf("failed to delete watchlist: %w", err)
+	}
+
+	return channelIDs, nil
+}
@@ -0,0 +1,37 @@
+package token
+
+import (
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Token types carried in the "type" claim
+const (
+	TypeAccess    = "access"
+	TypeRefresh   = "refresh"
+	TypeTwoFactor = "two_factor" // Login challenge exchanged together with a second factor
+)
+
+// Claims are the claims of access, refresh and two-factor challenge tokens.
+// The user ID is carried in the registered "sub" claim.
+type Claims struct {
+	jwt.RegisteredClaims
+	Type         string `json:"type"`
+	FamilyID     string `json:"family_id,omitempty"`     // Session the token belongs to
+	TokenVersion uint   `json:"token_version,omitempty"` // Access and challenge tokens
+}
+
+// UserID parses the subject as a user ID
+func (c *Claims) UserID() (uint, error) {
+	id, err := strconv.ParseUint(c.Subject, 10, 32)
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+	return uint(id), nil
+}
+
+// Subject formats a user ID for the "sub" claim
+func Subject(userID uint) string {
+	return strconv.FormatUint(uint64(userID), 10)
+}
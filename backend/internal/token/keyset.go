@@ -0,0 +1,170 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// HMACKeyID is the kid of the shared-secret key. Tokens issued before key IDs
+// were introduced carry no kid and are checked against this key.
+const HMACKeyID = "hs256"
+
+var (
+	ErrUnknownKey     = errors.New("unknown signing key")
+	ErrUnsupportedKey = errors.New("unsupported key type")
+	ErrInvalidToken   = errors.New("invalid token")
+)
+
+// Key is a signing or verification key identified by its kid
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   interface{} // nil for verification-only keys
+	verifyKey interface{}
+}
+
+// NewHMACKey returns an HS256 key that both signs and verifies
+func NewHMACKey(secret []byte) *Key {
+	return &Key{
+		ID:        HMACKeyID,
+		Method:    jwt.SigningMethodHS256,
+		signKey:   secret,
+		verifyKey: secret,
+	}
+}
+
+// NewSigningKey returns an RS256 or EdDSA key for the private key. When id is
+// empty it is derived from the public key.
+func NewSigningKey(id string, privateKey crypto.PrivateKey) (*Key, error) {
+	switch key := privateKey.(type) {
+	case *rsa.PrivateKey:
+		return newAsymmetricKey(id, jwt.SigningMethodRS256, key, &key.PublicKey)
+	case ed25519.PrivateKey:
+		return newAsymmetricKey(id, jwt.SigningMethodEdDSA, key, key.Public())
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
+
+// NewVerificationKey returns a key that only verifies, such as a retired
+// signing key that is kept around while its tokens expire
+func NewVerificationKey(id string, publicKey crypto.PublicKey) (*Key, error) {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return newAsymmetricKey(id, jwt.SigningMethodRS256, nil, key)
+	case ed25519.PublicKey:
+		return newAsymmetricKey(id, jwt.SigningMethodEdDSA, nil, key)
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
+
+func newAsymmetricKey(id string, method jwt.SigningMethod, signKey interface{}, publicKey crypto.PublicKey) (*Key, error) {
+	if id == "" {
+		thumbprint, err := keyThumbprint(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		id = thumbprint
+	}
+
+	return &Key{
+		ID:        id,
+		Method:    method,
+		signKey:   signKey,
+		verifyKey: publicKey,
+	}, nil
+}
+
+// keyThumbprint derives a stable kid from the DER encoding of the public key
+func keyThumbprint(publicKey crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(sum[:12]), nil
+}
+
+/*
+ * KeySet signs new tokens with a single active key and verifies tokens against
+ * every key it holds, selected by the kid header. Rotating keys means making a
+ * new key active while keeping the old one for verification until the tokens
+ * it signed have expired.
+ */
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+}
+
+func NewKeySet(signing *Key, verification ...*Key) (*KeySet, error) {
+	if signing == nil || signing.signKey == nil {
+		return nil, errors.New("key set requires a signing key")
+	}
+
+	ks := &KeySet{
+		signing: signing,
+		keys:    map[string]*Key{signing.ID: signing},
+	}
+
+	for _, key := range verification {
+		if _, exists := ks.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		ks.keys[key.ID] = key
+	}
+
+	return ks, nil
+}
+
+// Sign issues a token for the claims with the active key
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	t := jwt.NewWithClaims(ks.signing.Method, claims)
+	t.Header["kid"] = ks.signing.ID
+	return t.SignedString(ks.signing.signKey)
+}
+
+// Keyfunc resolves the verification key for a token. The token's algorithm
+// must match the key's so a public key can never be used as an HMAC secret.
+func (ks *KeySet) Keyfunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		kid = HMACKeyID
+	}
+
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	if t.Method.Alg() != key.Method.Alg() {
+		return nil, ErrInvalidToken
+	}
+
+	return key.verifyKey, nil
+}
+
+// Algorithms lists the signing algorithms of the keys in the set
+func (ks *KeySet) Algorithms() []string {
+	seen := make(map[string]bool)
+	var algorithms []string
+
+	for _, key := range ks.keys {
+		alg := key.Method.Alg()
+		if !seen[alg] {
+			seen[alg] = true
+			algorithms = append(algorithms, alg)
+		}
+	}
+
+	return algorithms
+}
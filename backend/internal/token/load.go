@@ -0,0 +1,122 @@
+package token
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"bytecast/configs"
+)
+
+// LoadKeySet builds the key set described by the JWT configuration. With an
+// asymmetric algorithm the shared secret, if still set, stays valid for
+// verification so switching algorithms does not end existing sessions.
+func LoadKeySet(cfg *configs.JWT) (*KeySet, error) {
+	verification, err := loadVerificationKeyFiles(cfg.VerificationKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Algorithm == "" || cfg.Algorithm == "HS256" {
+		return NewKeySet(NewHMACKey([]byte(cfg.Secret)), verification...)
+	}
+
+	data, err := os.ReadFile(cfg.PrivateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT private key: %w", err)
+	}
+
+	privateKey, err := ParsePrivateKeyPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JWT private key: %w", err)
+	}
+
+	signing, err := NewSigningKey(cfg.KeyID, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if signing.Method.Alg() != cfg.Algorithm {
+		return nil, fmt.Errorf("JWT private key does not match algorithm %s", cfg.Algorithm)
+	}
+
+	if cfg.Secret != "" {
+		verification = append(verification, &Key{
+			ID:        HMACKeyID,
+			Method:    jwt.SigningMethodHS256,
+			verifyKey: []byte(cfg.Secret),
+		})
+	}
+
+	return NewKeySet(signing, verification...)
+}
+
+// loadVerificationKeyFiles reads "kid=path" entries of PEM-encoded public keys
+func loadVerificationKeyFiles(entries []string) ([]*Key, error) {
+	var keys []*Key
+
+	for _, entry := range entries {
+		id, path, ok := strings.Cut(entry, "=")
+		if !ok || id == "" || path == "" {
+			return nil, fmt.Errorf("invalid JWT verification key %q, expected kid=path", entry)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT verification key %s: %w", id, err)
+		}
+
+		publicKey, err := ParsePublicKeyPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JWT verification key %s: %w", id, err)
+		}
+
+		key, err := NewVerificationKey(id, publicKey)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// ParsePrivateKeyPEM decodes a PKCS#8 or PKCS#1 private key
+func ParsePrivateKeyPEM(data []byte) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+}
+
+// ParsePublicKeyPEM decodes a PKIX or PKCS#1 public key
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+}
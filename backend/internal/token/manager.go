@@ -0,0 +1,80 @@
+package token
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+/*
+ * Manager issues and validates tokens. Every token carries the configured
+ * issuer and audience, and parsing enforces them together with the expiry,
+ * not-before and issued-at times, allowing for a small clock skew.
+ */
+type Manager struct {
+	keys     *KeySet
+	issuer   string
+	audience string
+	leeway   time.Duration
+}
+
+func NewManager(keys *KeySet, issuer, audience string, leeway time.Duration) *Manager {
+	return &Manager{
+		keys:     keys,
+		issuer:   issuer,
+		audience: audience,
+		leeway:   leeway,
+	}
+}
+
+// Issue signs a token of the given type for the user that expires after ttl.
+// The returned claims include the generated token ID.
+func (m *Manager) Issue(userID uint, tokenType string, ttl time.Duration, claims Claims) (string, *Claims, error) {
+	now := time.Now()
+
+	claims.Type = tokenType
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        uuid.NewString(),
+		Subject:   Subject(userID),
+		Issuer:    m.issuer,
+		Audience:  jwt.ClaimStrings{m.audience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	}
+
+	signed, err := m.keys.Sign(&claims)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return signed, &claims, nil
+}
+
+// Parse validates the token and checks that it is of the expected type
+func (m *Manager) Parse(tokenString, tokenType string) (*Claims, error) {
+	claims := &Claims{}
+
+	t, err := jwt.ParseWithClaims(tokenString, claims, m.keys.Keyfunc,
+		jwt.WithValidMethods(m.keys.Algorithms()),
+		jwt.WithIssuer(m.issuer),
+		jwt.WithAudience(m.audience),
+		jwt.WithLeeway(m.leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil || !t.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	if claims.Type != tokenType || claims.ID == "" {
+		return nil, ErrInvalidToken
+	}
+
+	if _, err := claims.UserID(); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
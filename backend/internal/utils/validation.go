@@ -33,6 +33,12 @@ func formatValidationError(e validator.FieldError) string {
 		return "Value is too long"
 	case "alphanum":
 		return "Only alphanumeric characters are allowed"
+	case "oneof":
+		return "Must be one of: " + e.Param()
+	case "timezone":
+		return "Unknown time zone"
+	case "bcp47_language_tag":
+		return "Invalid language tag"
 	default:
 		return "Invalid value"
 	}
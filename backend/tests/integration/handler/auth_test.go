@@ -6,16 +6,19 @@ import (
     "net/http"
     "net/http/httptest"
     "testing"
+    "time"
 
     "github.com/gin-gonic/gin"
     "github.com/stretchr/testify/assert"
-    "gorm.io/driver/postgres"
     "gorm.io/gorm"
 
     "bytecast/api/handler"
+    "bytecast/api/middleware"
+    "bytecast/api/utils"
     "bytecast/configs"
-    "bytecast/internal/models"
+    "bytecast/internal/mailer"
     "bytecast/internal/services"
+    "bytecast/internal/token"
 )
 
 type testServer struct {
@@ -25,20 +28,11 @@ type testServer struct {
 }
 
 func setupTestServer(t *testing.T) *testServer {
-    dsn := "host=localhost user=postgres password=bytecast dbname=bytecast_test port=5433 sslmode=disable"
-    db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
-    if err != nil {
-        t.Fatalf("Failed to connect to test database: %v", err)
-    }
-
-    db.Exec("DROP TABLE IF EXISTS revoked_tokens, users CASCADE")
-
-    if err := db.AutoMigrate(&models.User{}, &models.RevokedToken{}); err != nil {
-        t.Fatalf("Failed to run migrations: %v", err)
-    }
+    db := newSQLiteDB(t)
 
     gin.SetMode(gin.TestMode)
     engine := gin.New()
+    engine.Use(middleware.ErrorHandler())
 
     cfg := &configs.Config{
         Server: configs.Server{
@@ -51,9 +45,16 @@ func setupTestServer(t *testing.T) *testServer {
         },
     }
 
-    authService := services.NewAuthService(db, cfg.JWT.Secret)
-    authHandler := handler.NewAuthHandler(authService, cfg)
-    authHandler.RegisterRoutes(engine)
+    keys, err := token.LoadKeySet(&cfg.JWT)
+    if err != nil {
+        t.Fatalf("Failed to load JWT keys: %v", err)
+    }
+    tokens := token.NewManager(keys, "bytecast", "bytecast-api", 30*time.Second)
+
+    authService := services.NewAuthService(db, services.NewWatchlistService(db, cfg, nil), tokens)
+    accountService := services.NewAccountService(db, authService, nil, mailer.NewLogMailer(nil), "http://localhost:4200")
+    authHandler := handler.NewAuthHandler(authService, accountService, nil, cfg)
+    authHandler.RegisterRoutes(engine, middleware.AuthMiddleware(tokens, authService, nil), nil)
 
     return &testServer{
         db:          db,
@@ -102,7 +103,7 @@ func TestAuthHandler_Register(t *testing.T) {
     for _, tt := range tests {
         t.Run(tt.name, func(t *testing.T) {
             body, _ := json.Marshal(tt.input)
-            req := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewBuffer(body))
+            req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", bytes.NewBuffer(body))
             req.Header.Set("Content-Type", "application/json")
             w := httptest.NewRecorder()
             server.engine.ServeHTTP(w, req)
@@ -122,15 +123,15 @@ func TestAuthHandler_Logout(t *testing.T) {
     }
 
     registerBody, _ := json.Marshal(registerData)
-    req := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewBuffer(registerBody))
+    req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", bytes.NewBuffer(registerBody))
     req.Header.Set("Content-Type", "application/json")
     w := httptest.NewRecorder()
     server.engine.ServeHTTP(w, req)
     assert.Equal(t, http.StatusCreated, w.Code)
 
     // Login to get tokens and cookie
-    loginBody, _ := json.Marshal(registerData)
-    req = httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBuffer(loginBody))
+    loginBody, _ := json.Marshal(map[string]string{"identifier": "testuser", "password": "password123"})
+    req = httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(loginBody))
     req.Header.Set("Content-Type", "application/json")
     w = httptest.NewRecorder()
     server.engine.ServeHTTP(w, req)
@@ -155,7 +156,7 @@ func TestAuthHandler_Logout(t *testing.T) {
         {
             name:       "Valid token logout",
             cookie:     refreshCookie,
-            wantStatus: http.StatusNoContent,
+            wantStatus: http.StatusOK,
         },
         {
             name:       "Invalid token logout",
@@ -171,9 +172,10 @@ func TestAuthHandler_Logout(t *testing.T) {
 
     for _, tt := range tests {
         t.Run(tt.name, func(t *testing.T) {
-            req := httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
+            req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/logout", nil)
             req.Header.Set("Content-Type", "application/json")
             req.AddCookie(tt.cookie)
+            req.Header.Set(utils.CSRFHeaderName, utils.CSRFToken(tt.cookie.Value))
             w := httptest.NewRecorder()
             server.engine.ServeHTTP(w, req)
 
@@ -194,15 +196,15 @@ func TestAuthHandler_RefreshWithRevokedToken(t *testing.T) {
 
     // Register
     registerBody, _ := json.Marshal(userData)
-    req := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewBuffer(registerBody))
+    req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", bytes.NewBuffer(registerBody))
     req.Header.Set("Content-Type", "application/json")
     w := httptest.NewRecorder()
     server.engine.ServeHTTP(w, req)
     assert.Equal(t, http.StatusCreated, w.Code)
 
     // Login to get tokens
-    loginBody, _ := json.Marshal(userData)
-    req = httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBuffer(loginBody))
+    loginBody, _ := json.Marshal(map[string]string{"identifier": "testuser", "password": "password123"})
+    req = httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(loginBody))
     req.Header.Set("Content-Type", "application/json")
     w = httptest.NewRecorder()
     server.engine.ServeHTTP(w, req)
@@ -220,17 +222,19 @@ func TestAuthHandler_RefreshWithRevokedToken(t *testing.T) {
     assert.NotNil(t, refreshCookie, "Refresh token cookie not found")
 
     // Logout to revoke the token
-    req = httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
+    req = httptest.NewRequest(http.MethodPost, "/api/v1/auth/logout", nil)
     req.Header.Set("Content-Type", "application/json")
     req.AddCookie(refreshCookie)
+    req.Header.Set(utils.CSRFHeaderName, utils.CSRFToken(refreshCookie.Value))
     w = httptest.NewRecorder()
     server.engine.ServeHTTP(w, req)
-    assert.Equal(t, http.StatusNoContent, w.Code)
+    assert.Equal(t, http.StatusOK, w.Code)
 
     // Attempt to refresh with revoked token
-    req = httptest.NewRequest(http.MethodPost, "/auth/refresh", nil)
+    req = httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", nil)
     req.Header.Set("Content-Type", "application/json")
     req.AddCookie(refreshCookie)
+    req.Header.Set(utils.CSRFHeaderName, utils.CSRFToken(refreshCookie.Value))
     w = httptest.NewRecorder()
     server.engine.ServeHTTP(w, req)
 
@@ -0,0 +1,60 @@
+package handler_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"bytecast/internal/models"
+)
+
+// TestWatchlist mirrors models.Watchlist without the Postgres-only color check constraint
+type TestWatchlist struct {
+	gorm.Model
+	UserID      uint    `gorm:"index;not null"`
+	Name        string  `gorm:"size:255;not null"`
+	Description string  `gorm:"type:text"`
+	Color       string  `gorm:"size:7;not null"`
+	ShareSlug   *string `gorm:"uniqueIndex;size:32"`
+	SharedAt    *time.Time
+	Channels    []*models.Channel `gorm:"many2many:watchlist_channels;foreignKey:ID;joinForeignKey:WatchlistID;References:ID;joinReferences:ChannelID"`
+	Videos      []*models.Video   `gorm:"many2many:watchlist_videos;foreignKey:ID;joinForeignKey:WatchlistID;References:ID;joinReferences:VideoID"`
+}
+
+// TableName specifies the table name for the TestWatchlist model
+func (TestWatchlist) TableName() string {
+	return "watchlists"
+}
+
+// newSQLiteDB opens a fresh in-memory database with the full schema
+func newSQLiteDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(
+		&models.User{},
+		&models.Channel{},
+		&TestWatchlist{},
+		&models.Video{},
+		&models.WatchlistMember{},
+		&models.WatchlistInvitation{},
+		&models.HubSubscription{},
+		&models.RevokedToken{},
+		&models.Session{},
+		&models.UserToken{},
+		&models.LoginAttempt{},
+		&models.RecoveryCode{},
+		&models.UserIdentity{},
+		&models.PersonalAccessToken{},
+		&models.InviteCode{},
+		&models.AuditEvent{},
+		&models.UserPreferences{},
+	)
+	require.NoError(t, err)
+
+	return db
+}
@@ -7,36 +7,23 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
 	"bytecast/api/handler"
 	"bytecast/api/middleware"
 	"bytecast/configs"
+	"bytecast/internal/mailer"
 	"bytecast/internal/models"
 	"bytecast/internal/services"
+	"bytecast/internal/token"
 )
 
-// TestWatchlist is a test-specific version of the Watchlist model without the check constraint
-type TestWatchlist struct {
-	gorm.Model
-	UserID      uint   `gorm:"index;not null"`
-	Name        string `gorm:"size:255;not null"`
-	Description string `gorm:"type:text"`
-	Color       string `gorm:"size:7;not null"`
-	Channels    []*models.Channel `gorm:"many2many:watchlist_channels;foreignKey:ID;joinForeignKey:WatchlistID;References:ID;joinReferences:ChannelID"`
-}
-
-// TableName specifies the table name for the TestWatchlist model
-func (TestWatchlist) TableName() string {
-	return "watchlists"
-}
-
 type watchlistTestServer struct {
 	db               *gorm.DB
 	engine           *gin.Engine
@@ -46,15 +33,7 @@ type watchlistTestServer struct {
 }
 
 func setupWatchlistTestServer(t *testing.T) *watchlistTestServer {
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	if err != nil {
-		t.Fatalf("Failed to connect to test database: %v", err)
-	}
-
-	// Run migrations for all models except Watchlist
-	if err := db.AutoMigrate(&models.User{}, &models.RevokedToken{}, &models.Channel{}, &TestWatchlist{}); err != nil {
-		t.Fatalf("Failed to run migrations: %v", err)
-	}
+	db := newSQLiteDB(t)
 
 	// Create test user
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
@@ -90,17 +69,23 @@ func setupWatchlistTestServer(t *testing.T) *watchlistTestServer {
 	}
 
 	// Initialize services
-	watchlistService := services.NewWatchlistService(db, cfg)
-	authService := services.NewAuthService(db, watchlistService, cfg.JWT.Secret)
+	watchlistService := services.NewWatchlistService(db, cfg, nil)
+	keys, err := token.LoadKeySet(&cfg.JWT)
+	if err != nil {
+		t.Fatalf("Failed to load JWT keys: %v", err)
+	}
+	tokens := token.NewManager(keys, "bytecast", "bytecast-api", 30*time.Second)
+	authService := services.NewAuthService(db, watchlistService, tokens)
 
 	// Initialize handlers
-	authHandler := handler.NewAuthHandler(authService, cfg)
-	watchlistHandler := handler.NewWatchlistHandler(watchlistService)
+	accountService := services.NewAccountService(db, authService, watchlistService, mailer.NewLogMailer(nil), "http://localhost:4200")
+	authHandler := handler.NewAuthHandler(authService, accountService, nil, cfg)
+	watchlistHandler := handler.NewWatchlistHandler(watchlistService, nil)
 
 	// Register routes
-	authHandler.RegisterRoutes(engine)
-	authMiddleware := middleware.AuthMiddleware([]byte(cfg.JWT.Secret))
-	watchlistHandler.RegisterRoutes(engine, authMiddleware)
+	authMiddleware := middleware.AuthMiddleware(tokens, authService, authService)
+	authHandler.RegisterRoutes(engine, authMiddleware, nil)
+	watchlistHandler.RegisterRoutes(engine, authMiddleware, nil)
 
 	return &watchlistTestServer{
 		db:               db,
@@ -131,17 +116,18 @@ func getAuthTokenForTest(t *testing.T, server *watchlistTestServer) string {
 		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
 	}
 
-	var loginResp map[string]string
+	var loginResp struct {
+		AccessToken string `json:"access_token"`
+	}
 	if err := json.Unmarshal(w.Body.Bytes(), &loginResp); err != nil {
 		t.Fatalf("Failed to unmarshal login response: %v", err)
 	}
 
-	token, exists := loginResp["access_token"]
-	if !exists {
+	if loginResp.AccessToken == "" {
 		t.Fatal("Access token not found in login response")
 	}
 
-	return token
+	return loginResp.AccessToken
 }
 
 func TestCreateWatchlist(t *testing.T) {
@@ -0,0 +1,108 @@
+package database_test
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+/*
+ * The models as of the last release that created its tables with AutoMigrate.
+ * They keep the original type names, because GORM derives the join table
+ * columns from them. AutoMigrate on these builds the schema that deployments
+ * from before versioned migrations still run on.
+ */
+
+type User struct {
+	gorm.Model
+	Email        string `gorm:"uniqueIndex;not null"`
+	Username     string `gorm:"uniqueIndex;not null;size:24"`
+	PasswordHash string `gorm:"not null"`
+}
+
+func (User) TableName() string {
+	return "users"
+}
+
+type RevokedToken struct {
+	ID        uint      `gorm:"primaryKey"`
+	TokenHash string    `gorm:"type:varchar(255);uniqueIndex;not null"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+	UserID    uint      `gorm:"index;not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (RevokedToken) TableName() string {
+	return "revoked_tokens"
+}
+
+type Channel struct {
+	gorm.Model
+	YoutubeID    string       `gorm:"uniqueIndex;size:255;not null"`
+	Title        string       `gorm:"size:255;not null"`
+	Description  string       `gorm:"type:text"`
+	ThumbnailURL string       `gorm:"size:512"`
+	CustomName   string       `gorm:"size:255"`
+	Watchlists   []*Watchlist `gorm:"many2many:watchlist_channels;"`
+}
+
+func (Channel) TableName() string {
+	return "channels"
+}
+
+type Watchlist struct {
+	gorm.Model
+	UserID      uint       `gorm:"index;not null"`
+	Name        string     `gorm:"size:255;not null"`
+	Description string     `gorm:"type:text"`
+	Color       string     `gorm:"size:7;not null;check:color ~ '^#[a-fA-F0-9]{6}$'"`
+	Channels    []*Channel `gorm:"many2many:watchlist_channels;"`
+	Videos      []*Video   `gorm:"many2many:watchlist_videos;"`
+}
+
+func (Watchlist) TableName() string {
+	return "watchlists"
+}
+
+type HubSubscription struct {
+	gorm.Model
+	ChannelID    string `gorm:"uniqueIndex;not null"`
+	SubscribedAt time.Time
+	ExpiresAt    time.Time
+	IsActive     bool
+	LeaseSeconds int
+	Secret       string
+}
+
+func (HubSubscription) TableName() string {
+	return "hub_subscriptions"
+}
+
+type Video struct {
+	gorm.Model
+	YoutubeID    string `gorm:"uniqueIndex;size:255;not null"`
+	ChannelID    uint
+	Channel      Channel `gorm:"foreignKey:ChannelID"`
+	Title        string  `gorm:"size:255;not null"`
+	Description  string  `gorm:"type:text"`
+	ThumbnailURL string  `gorm:"size:512"`
+	Duration     string  `gorm:"size:32"`
+	PublishedAt  time.Time
+	Watchlists   []*Watchlist `gorm:"many2many:watchlist_videos;"`
+}
+
+func (Video) TableName() string {
+	return "youtube_videos"
+}
+
+func baselineModels() []interface{} {
+	return []interface{}{
+		&User{},
+		&RevokedToken{},
+		&Channel{},
+		&Watchlist{},
+		&HubSubscription{},
+		&Video{},
+	}
+}
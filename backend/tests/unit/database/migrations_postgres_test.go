@@ -0,0 +1,184 @@
+package database_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"bytecast/internal/database"
+	"bytecast/internal/database/migrations"
+	"bytecast/internal/models"
+)
+
+/*
+ * The migrations are written for Postgres, so these tests need a server. Set
+ * TEST_DATABASE_URL to run them; each test works in a schema of its own that
+ * is dropped afterwards.
+ */
+func newPostgresDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+
+	// A single connection, so the search path applies to every query
+	sqlDB.SetMaxOpenConns(1)
+	schema := fmt.Sprintf("migrations_test_%d", time.Now().UnixNano())
+	require.NoError(t, db.Exec("CREATE SCHEMA "+schema).Error)
+	t.Cleanup(func() {
+		db.Exec("DROP SCHEMA " + schema + " CASCADE")
+		sqlDB.Close()
+	})
+	require.NoError(t, db.Exec("SET search_path TO "+schema).Error)
+
+	return db
+}
+
+func currentModels() []interface{} {
+	return []interface{}{
+		&models.User{},
+		&models.Channel{},
+		&models.Watchlist{},
+		&models.Video{},
+		&models.WatchlistMember{},
+		&models.WatchlistInvitation{},
+		&models.HubSubscription{},
+		&models.RevokedToken{},
+		&models.Session{},
+		&models.UserToken{},
+		&models.LoginAttempt{},
+		&models.RecoveryCode{},
+		&models.UserIdentity{},
+		&models.PersonalAccessToken{},
+		&models.InviteCode{},
+		&models.AuditEvent{},
+		&models.UserPreferences{},
+	}
+}
+
+func migrateUp(t *testing.T, db *gorm.DB) {
+	migrator, err := database.NewMigrator(db, migrations.FS)
+	require.NoError(t, err)
+	_, err = migrator.Up()
+	require.NoError(t, err)
+}
+
+// assertSchemaMatchesModels checks that every model has its table, with a
+// column of the right nullability for each field and nothing else, and its
+// indexes and join tables
+func assertSchemaMatchesModels(t *testing.T, db *gorm.DB) {
+	for _, model := range currentModels() {
+		stmt := &gorm.Statement{DB: db}
+		require.NoError(t, stmt.Parse(model))
+		table := stmt.Schema.Table
+		if !assert.True(t, db.Migrator().HasTable(table), "table %s is missing", table) {
+			continue
+		}
+
+		columns, err := db.Migrator().ColumnTypes(table)
+		require.NoError(t, err)
+		nullable := make(map[string]bool, len(columns))
+		for _, column := range columns {
+			nullable[column.Name()], _ = column.Nullable()
+		}
+
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" || field.IgnoreMigration {
+				continue
+			}
+			isNullable, ok := nullable[field.DBName]
+			if !assert.True(t, ok, "column %s.%s is missing", table, field.DBName) {
+				continue
+			}
+			if !field.PrimaryKey {
+				assert.Equal(t, !field.NotNull, isNullable, "nullability of %s.%s", table, field.DBName)
+			}
+			delete(nullable, field.DBName)
+		}
+		assert.Empty(t, nullable, "%s has columns no field maps to", table)
+
+		for name := range stmt.Schema.ParseIndexes() {
+			assert.True(t, db.Migrator().HasIndex(model, name), "index %s on %s is missing", name, table)
+		}
+		for _, relationship := range stmt.Schema.Relationships.Relations {
+			if relationship.JoinTable != nil {
+				assert.True(t, db.Migrator().HasTable(relationship.JoinTable.Table), "join table %s is missing", relationship.JoinTable.Table)
+			}
+		}
+	}
+}
+
+func TestMigrations_FreshDatabase(t *testing.T) {
+	db := newPostgresDB(t)
+
+	migrateUp(t, db)
+	assertSchemaMatchesModels(t, db)
+
+	// Every migration can be reverted and applied again
+	migrator, err := database.NewMigrator(db, migrations.FS)
+	require.NoError(t, err)
+	statuses, err := migrator.Status()
+	require.NoError(t, err)
+	reverted, err := migrator.Down(len(statuses))
+	require.NoError(t, err)
+	assert.Equal(t, len(statuses), reverted)
+	assert.False(t, db.Migrator().HasTable("users"))
+
+	migrateUp(t, db)
+	assertSchemaMatchesModels(t, db)
+}
+
+func TestMigrations_UpgradeBaselineDatabase(t *testing.T) {
+	db := newPostgresDB(t)
+	require.NoError(t, db.AutoMigrate(baselineModels()...))
+
+	user := &User{Email: "old@example.com", Username: "old", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	require.NoError(t, db.Create(&Watchlist{UserID: user.ID, Name: "Default", Color: "#3b82f6"}).Error)
+
+	migrateUp(t, db)
+	assertSchemaMatchesModels(t, db)
+
+	// Existing rows take the defaults of the new columns
+	var upgraded models.User
+	require.NoError(t, db.First(&upgraded, user.ID).Error)
+	assert.Equal(t, models.RoleUser, upgraded.Role)
+	assert.Zero(t, upgraded.TokenVersion)
+	assert.Nil(t, upgraded.EmailVerifiedAt)
+}
+
+func TestMigrations_WatchlistsWithoutColor(t *testing.T) {
+	db := newPostgresDB(t)
+	require.NoError(t, db.AutoMigrate(baselineModels()...))
+	require.NoError(t, db.Exec("ALTER TABLE watchlists DROP COLUMN color").Error)
+	require.NoError(t, db.Exec("INSERT INTO watchlists (user_id, name) VALUES (1, 'Old')").Error)
+
+	migrateUp(t, db)
+	assertSchemaMatchesModels(t, db)
+
+	var color string
+	require.NoError(t, db.Raw("SELECT color FROM watchlists WHERE name = 'Old'").Scan(&color).Error)
+	assert.Equal(t, "#3b82f6", color)
+}
+
+func TestMigrations_AdoptAutoMigratedDatabase(t *testing.T) {
+	db := newPostgresDB(t)
+
+	// Databases last started before versioned migrations already have everything
+	require.NoError(t, db.AutoMigrate(currentModels()...))
+
+	migrateUp(t, db)
+	assertSchemaMatchesModels(t, db)
+}
@@ -0,0 +1,143 @@
+package database_test
+
+import (
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"bytecast/internal/database"
+	"bytecast/internal/database/migrations"
+)
+
+func newSQLiteDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{})
+	require.NoError(t, err)
+	return db
+}
+
+func sqlFile(contents string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(contents)}
+}
+
+func testMigrations() fstest.MapFS {
+	return fstest.MapFS{
+		"0001_create_items.up.sql":     sqlFile("CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT NOT NULL);"),
+		"0001_create_items.down.sql":   sqlFile("DROP TABLE items;"),
+		"0002_add_item_color.up.sql":   sqlFile("ALTER TABLE items ADD COLUMN color TEXT; UPDATE items SET color = 'blue';"),
+		"0002_add_item_color.down.sql": sqlFile("ALTER TABLE items DROP COLUMN color;"),
+		"README.md":                    sqlFile("Ignored"),
+	}
+}
+
+func TestLoadMigrations_Embedded(t *testing.T) {
+	loaded, err := database.LoadMigrations(migrations.FS)
+	require.NoError(t, err)
+	require.NotEmpty(t, loaded)
+
+	for i, migration := range loaded {
+		assert.Equal(t, int64(i+1), migration.Version, "versions must be consecutive")
+		assert.NotEmpty(t, migration.Up)
+		assert.NotEmpty(t, migration.Down, "migration %d_%s has no down file", migration.Version, migration.Name)
+	}
+}
+
+func TestLoadMigrations_Invalid(t *testing.T) {
+	_, err := database.LoadMigrations(fstest.MapFS{"create_items.up.sql": sqlFile("SELECT 1;")})
+	assert.Error(t, err)
+
+	_, err = database.LoadMigrations(fstest.MapFS{"0001_items.down.sql": sqlFile("SELECT 1;")})
+	assert.Error(t, err, "a migration needs an up file")
+
+	_, err = database.LoadMigrations(fstest.MapFS{
+		"0001_items.up.sql": sqlFile("SELECT 1;"),
+		"0001_other.up.sql": sqlFile("SELECT 1;"),
+	})
+	assert.Error(t, err, "versions must be unique")
+}
+
+func TestMigrator_UpDownStatus(t *testing.T) {
+	db := newSQLiteDB(t)
+	migrator, err := database.NewMigrator(db, testMigrations())
+	require.NoError(t, err)
+
+	statuses, err := migrator.Status()
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.Nil(t, statuses[0].AppliedAt)
+	assert.Nil(t, statuses[1].AppliedAt)
+
+	applied, err := migrator.Up()
+	require.NoError(t, err)
+	assert.Equal(t, 2, applied)
+	assert.True(t, db.Migrator().HasColumn("items", "color"))
+
+	applied, err = migrator.Up()
+	require.NoError(t, err)
+	assert.Equal(t, 0, applied, "applied migrations are not run again")
+
+	statuses, err = migrator.Status()
+	require.NoError(t, err)
+	assert.NotNil(t, statuses[0].AppliedAt)
+	assert.NotNil(t, statuses[1].AppliedAt)
+
+	reverted, err := migrator.Down(1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reverted)
+	assert.False(t, db.Migrator().HasColumn("items", "color"))
+	assert.True(t, db.Migrator().HasTable("items"))
+
+	statuses, err = migrator.Status()
+	require.NoError(t, err)
+	assert.NotNil(t, statuses[0].AppliedAt)
+	assert.Nil(t, statuses[1].AppliedAt)
+
+	reverted, err = migrator.Down(5)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reverted)
+	assert.False(t, db.Migrator().HasTable("items"))
+}
+
+func TestMigrator_FailedMigrationIsRolledBack(t *testing.T) {
+	db := newSQLiteDB(t)
+	source := testMigrations()
+	source["0003_broken.up.sql"] = sqlFile("CREATE TABLE tags (id INTEGER); INSERT INTO missing VALUES (1);")
+	migrator, err := database.NewMigrator(db, source)
+	require.NoError(t, err)
+
+	applied, err := migrator.Up()
+	require.Error(t, err)
+	assert.Equal(t, 2, applied)
+	assert.False(t, db.Migrator().HasTable("tags"), "the failed migration must leave nothing behind")
+
+	statuses, err := migrator.Status()
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+	assert.NotNil(t, statuses[1].AppliedAt)
+	assert.Nil(t, statuses[2].AppliedAt)
+
+	// The broken migration was never recorded, so down reverts the one before it
+	reverted, err := migrator.Down(1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reverted)
+}
+
+func TestMigrator_DownRequiresDownFile(t *testing.T) {
+	db := newSQLiteDB(t)
+	source := testMigrations()
+	delete(source, "0002_add_item_color.down.sql")
+	migrator, err := database.NewMigrator(db, source)
+	require.NoError(t, err)
+
+	_, err = migrator.Up()
+	require.NoError(t, err)
+
+	reverted, err := migrator.Down(1)
+	assert.Error(t, err)
+	assert.Equal(t, 0, reverted)
+	assert.True(t, db.Migrator().HasColumn("items", "color"))
+}
@@ -0,0 +1,47 @@
+package mailer_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bytecast/configs"
+	"bytecast/internal/mailer"
+)
+
+func TestFileMailer_WritesMessages(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+
+	m, err := mailer.New(&configs.Mail{Driver: "file", Dir: dir, From: "no-reply@example.com"}, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, m.Send(mailer.Message{
+		To:      "user@example.com",
+		Subject: "Hello\r\nBcc: attacker@example.com",
+		Body:    "Line one\nLine two",
+	}))
+	require.NoError(t, m.Send(mailer.Message{To: "other@example.com", Subject: "Second"}))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	data, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	require.NoError(t, err)
+	content := string(data)
+
+	assert.Contains(t, content, "From: no-reply@example.com\r\n")
+	assert.Contains(t, content, "To: user@example.com\r\n")
+	assert.Contains(t, content, "Subject: HelloBcc: attacker@example.com\r\n")
+	assert.False(t, strings.Contains(content, "\r\nBcc:"))
+	assert.True(t, strings.HasSuffix(content, "\r\n\r\nLine one\r\nLine two"))
+}
+
+func TestNew_UnknownDriver(t *testing.T) {
+	_, err := mailer.New(&configs.Mail{Driver: "carrier-pigeon"}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,116 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"bytecast/api/middleware"
+)
+
+func newCORSEngine(origins ...string) *gin.Engine {
+	config := middleware.DefaultCORSConfig()
+	config.AllowOrigins = origins
+	config.MaxAge = 10 * time.Minute
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(middleware.CORSWithConfig(config))
+	engine.GET("/api", func(c *gin.Context) { c.Status(http.StatusOK) })
+	engine.OPTIONS("/api", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return engine
+}
+
+func corsRequest(engine *gin.Engine, method, origin string, preflight bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/api", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if preflight {
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORS_AllowedOrigin(t *testing.T) {
+	engine := newCORSEngine("https://app.example.com")
+
+	w := corsRequest(engine, http.MethodGet, "https://app.example.com", false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Contains(t, w.Header().Values("Vary"), "Origin")
+}
+
+func TestCORS_DisallowedOrigin(t *testing.T) {
+	engine := newCORSEngine("https://app.example.com")
+
+	// Simple requests go through, but without headers the browser hides the response
+	w := corsRequest(engine, http.MethodGet, "https://evil.example.org", false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Contains(t, w.Header().Values("Vary"), "Origin")
+
+	w = corsRequest(engine, http.MethodOptions, "https://evil.example.org", true)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Methods"))
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	engine := newCORSEngine("https://app.example.com")
+
+	w := corsRequest(engine, http.MethodOptions, "https://app.example.com", true)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "X-CSRF-Token")
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), "PATCH")
+	assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+
+	// An OPTIONS request that is not a preflight reaches the route
+	w = corsRequest(engine, http.MethodOptions, "https://app.example.com", false)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCORS_WildcardSubdomains(t *testing.T) {
+	engine := newCORSEngine("https://*.example.com", "http://localhost:4200")
+
+	allowed := []string{
+		"https://app.example.com",
+		"https://preview.app.example.com",
+		"HTTPS://App.Example.com",
+		"http://localhost:4200",
+	}
+	for _, origin := range allowed {
+		w := corsRequest(engine, http.MethodGet, origin, false)
+		assert.Equal(t, origin, w.Header().Get("Access-Control-Allow-Origin"), origin)
+	}
+
+	rejected := []string{
+		"https://example.com",
+		"http://app.example.com",
+		"https://evilexample.com",
+		"https://app.example.com.evil.org",
+		"https://app.example.com:8443",
+		"https://evil.org/.example.com",
+		"http://localhost:4201",
+	}
+	for _, origin := range rejected {
+		w := corsRequest(engine, http.MethodGet, origin, false)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"), origin)
+	}
+}
+
+func TestCORS_NoOrigin(t *testing.T) {
+	engine := newCORSEngine("https://app.example.com")
+
+	w := corsRequest(engine, http.MethodGet, "", false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
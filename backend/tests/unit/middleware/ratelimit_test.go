@@ -0,0 +1,91 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"bytecast/api/middleware"
+	"bytecast/internal/ratelimit"
+)
+
+// newRateLimitEngine limits /api to two requests an hour. A "user" query
+// parameter stands in for AuthMiddleware.
+func newRateLimitEngine(limiter *middleware.RateLimiter) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/api", func(c *gin.Context) {
+		if user := c.Query("user"); user != "" {
+			c.Set("user_id", user)
+		}
+	}, limiter.Limit(middleware.RateLimitAPI), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return engine
+}
+
+func newTestRateLimiter() *middleware.RateLimiter {
+	return middleware.NewRateLimiter(ratelimit.NewMemoryStore(), map[string]ratelimit.Limit{
+		middleware.RateLimitAPI: {Requests: 2, Period: time.Hour},
+	})
+}
+
+func limitedRequest(engine *gin.Engine, query, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api"+query, nil)
+	req.RemoteAddr = remoteAddr
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimit_HeadersAndRejection(t *testing.T) {
+	engine := newRateLimitEngine(newTestRateLimiter())
+
+	w := limitedRequest(engine, "", "192.0.2.1:1234")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "1800", w.Header().Get("RateLimit-Reset"))
+
+	w = limitedRequest(engine, "", "192.0.2.1:1234")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+
+	w = limitedRequest(engine, "", "192.0.2.1:1234")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1800", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), middleware.ErrRateLimited.Error())
+}
+
+func TestRateLimit_KeyedByUserOrIP(t *testing.T) {
+	engine := newRateLimitEngine(newTestRateLimiter())
+
+	for i := 0; i < 2; i++ {
+		limitedRequest(engine, "?user=1", "192.0.2.1:1234")
+	}
+	assert.Equal(t, http.StatusTooManyRequests, limitedRequest(engine, "?user=1", "192.0.2.9:1234").Code)
+
+	// Another user behind the same address has their own bucket, as does the address itself
+	assert.Equal(t, http.StatusOK, limitedRequest(engine, "?user=2", "192.0.2.1:1234").Code)
+	assert.Equal(t, http.StatusOK, limitedRequest(engine, "", "192.0.2.1:1234").Code)
+}
+
+func TestRateLimit_DisabledLetsEverythingThrough(t *testing.T) {
+	engines := []*gin.Engine{
+		newRateLimitEngine(nil),
+		newRateLimitEngine(middleware.NewRateLimiter(ratelimit.NewMemoryStore(), map[string]ratelimit.Limit{
+			middleware.RateLimitAPI: {Requests: 0, Period: time.Hour},
+		})),
+	}
+	for _, engine := range engines {
+		for i := 0; i < 5; i++ {
+			w := limitedRequest(engine, "", "192.0.2.1:1234")
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+		}
+	}
+}
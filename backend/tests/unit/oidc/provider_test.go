@@ -0,0 +1,121 @@
+package oidc_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bytecast/internal/oidc"
+)
+
+func newFlow(t *testing.T) *oidc.AuthFlow {
+	flow, err := oidc.NewAuthFlow()
+	require.NoError(t, err)
+	return flow
+}
+
+func TestProvider_AuthCodeURL(t *testing.T) {
+	fake := newFakeProvider(t)
+	provider := oidc.NewProvider("google", fake.config())
+	flow := newFlow(t)
+
+	authURL, err := url.Parse(provider.AuthCodeURL(flow))
+	require.NoError(t, err)
+
+	q := authURL.Query()
+	assert.Equal(t, testClientID, q.Get("client_id"))
+	assert.Equal(t, flow.State, q.Get("state"))
+	assert.Equal(t, flow.Nonce, q.Get("nonce"))
+	assert.Equal(t, "S256", q.Get("code_challenge_method"))
+	assert.NotEmpty(t, q.Get("code_challenge"))
+	assert.NotContains(t, authURL.String(), flow.Verifier)
+	assert.Equal(t, "openid email profile", q.Get("scope"))
+}
+
+func TestProvider_Exchange(t *testing.T) {
+	fake := newFakeProvider(t)
+	provider := oidc.NewProvider("google", fake.config())
+	flow := newFlow(t)
+
+	identity, err := provider.Exchange(context.Background(), signIn(t, provider, flow), flow)
+	require.NoError(t, err)
+
+	assert.Equal(t, "google-user-1", identity.Subject)
+	assert.Equal(t, "jane@example.com", identity.Email)
+	assert.True(t, identity.EmailVerified)
+}
+
+func TestProvider_ExchangeRequiresVerifier(t *testing.T) {
+	fake := newFakeProvider(t)
+	provider := oidc.NewProvider("google", fake.config())
+	flow := newFlow(t)
+	code := signIn(t, provider, flow)
+
+	stolen := &oidc.AuthFlow{State: flow.State, Nonce: flow.Nonce, Verifier: newFlow(t).Verifier}
+	_, err := provider.Exchange(context.Background(), code, stolen)
+	assert.Error(t, err)
+}
+
+func TestProvider_ExchangeChecksNonce(t *testing.T) {
+	fake := newFakeProvider(t)
+	provider := oidc.NewProvider("google", fake.config())
+	flow := newFlow(t)
+	code := signIn(t, provider, flow)
+
+	flow.Nonce = "replayed"
+	_, err := provider.Exchange(context.Background(), code, flow)
+	assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+}
+
+func TestProvider_ExchangeRejectsInvalidClaims(t *testing.T) {
+	cases := map[string]struct {
+		claim string
+		value interface{}
+	}{
+		"audience": {"aud", "someone-else"},
+		"issuer":   {"iss", "https://evil.example.com"},
+		"expired":  {"exp", time.Now().Add(-time.Hour).Unix()},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fake := newFakeProvider(t)
+			fake.setClaim(tc.claim, tc.value)
+			provider := oidc.NewProvider("google", fake.config())
+			flow := newFlow(t)
+
+			_, err := provider.Exchange(context.Background(), signIn(t, provider, flow), flow)
+			assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+		})
+	}
+}
+
+func TestProvider_EmailVerifiedAsString(t *testing.T) {
+	fake := newFakeProvider(t)
+	fake.setClaim("email_verified", "true")
+	provider := oidc.NewProvider("google", fake.config())
+	flow := newFlow(t)
+
+	identity, err := provider.Exchange(context.Background(), signIn(t, provider, flow), flow)
+	require.NoError(t, err)
+	assert.True(t, identity.EmailVerified)
+}
+
+func TestProvider_RefetchesRotatedKeys(t *testing.T) {
+	fake := newFakeProvider(t)
+	provider := oidc.NewProvider("google", fake.config())
+
+	flow := newFlow(t)
+	_, err := provider.Exchange(context.Background(), signIn(t, provider, flow), flow)
+	require.NoError(t, err)
+
+	fake.rotateKey("key-2")
+
+	flow = newFlow(t)
+	_, err = provider.Exchange(context.Background(), signIn(t, provider, flow), flow)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,73 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bytecast/internal/ratelimit"
+)
+
+var start = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func TestBucket_BurstThenRefill(t *testing.T) {
+	limit := ratelimit.Limit{Requests: 3, Period: 3 * time.Second}
+	var bucket ratelimit.Bucket
+
+	for want := 2; want >= 0; want-- {
+		result := bucket.Take(limit, start)
+		require.True(t, result.Allowed)
+		assert.Equal(t, want, result.Remaining)
+		assert.Zero(t, result.RetryAfter)
+	}
+
+	result := bucket.Take(limit, start)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 3, result.Limit)
+	assert.Equal(t, time.Second, result.RetryAfter)
+	assert.Equal(t, 3*time.Second, result.ResetAfter)
+
+	// One token comes back per second
+	result = bucket.Take(limit, start.Add(time.Second))
+	assert.True(t, result.Allowed)
+	result = bucket.Take(limit, start.Add(time.Second))
+	assert.False(t, result.Allowed)
+}
+
+func TestBucket_NeverExceedsCapacity(t *testing.T) {
+	limit := ratelimit.Limit{Requests: 2, Period: time.Minute}
+	var bucket ratelimit.Bucket
+
+	bucket.Take(limit, start)
+	result := bucket.Take(limit, start.Add(24*time.Hour))
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+}
+
+func TestLimit_Enabled(t *testing.T) {
+	assert.True(t, ratelimit.Limit{Requests: 1, Period: time.Second}.Enabled())
+	assert.False(t, ratelimit.Limit{Requests: 0, Period: time.Second}.Enabled())
+	assert.False(t, ratelimit.Limit{Requests: 1}.Enabled())
+}
+
+func TestMemoryStore_KeysAreIndependent(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	limit := ratelimit.Limit{Requests: 1, Period: time.Hour}
+	ctx := context.Background()
+
+	result, err := store.Take(ctx, "a", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	result, err = store.Take(ctx, "a", limit)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Greater(t, result.RetryAfter, 59*time.Minute)
+
+	result, err = store.Take(ctx, "b", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+}
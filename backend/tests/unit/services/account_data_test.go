@@ -0,0 +1,119 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"bytecast/internal/models"
+	"bytecast/internal/services"
+)
+
+// seedAccountData gives the "rotate" user an active and a trashed watchlist, a
+// membership in another user's watchlist and a session. It returns the channel
+// only the user references and the one shared with the other user.
+func seedAccountData(t *testing.T, db *gorm.DB, auth *services.AuthService) (user *models.User, owned, shared *models.Channel) {
+	user, err := auth.FindByIdentifier("rotate")
+	require.NoError(t, err)
+
+	watchlist := seedWatchlist(t, db, user.ID, "Mine")
+	owned = seedChannel(t, db, watchlist.ID, "UC-owned")
+
+	other := seedUser(t, db, "other")
+	otherWatchlist := seedWatchlist(t, db, other.ID, "Theirs")
+	shared = seedChannel(t, db, otherWatchlist.ID, "UC-shared")
+	require.NoError(t, db.Exec("INSERT INTO watchlist_channels (watchlist_id, channel_id) VALUES (?, ?)", watchlist.ID, shared.ID).Error)
+
+	trashed := seedWatchlist(t, db, user.ID, "Old")
+	require.NoError(t, db.Delete(trashed).Error)
+
+	addMember(t, services.NewWatchlistService(db, nil, nil), otherWatchlist.ID, other.ID, user, models.WatchlistRoleViewer)
+
+	_, _, err = auth.LoginUser("rotate", "password123", services.ClientInfo{UserAgent: "Firefox"})
+	require.NoError(t, err)
+
+	return user, owned, shared
+}
+
+func TestAccountData_Export(t *testing.T) {
+	db, auth, svc, _ := newAccountService(t)
+	user, _, _ := seedAccountData(t, db, auth)
+
+	export, err := svc.ExportAccount(user.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, "rotate@example.com", export.User.Email)
+	require.Len(t, export.Watchlists, 2)
+	assert.Equal(t, "Mine", export.Watchlists[0].Name)
+	assert.Len(t, export.Watchlists[0].Channels, 2)
+	assert.Nil(t, export.Watchlists[0].DeletedAt)
+	assert.NotNil(t, export.Watchlists[1].DeletedAt)
+	require.Len(t, export.Memberships, 1)
+	assert.Equal(t, models.WatchlistRoleViewer, export.Memberships[0].Role)
+	require.Len(t, export.Sessions, 1)
+	assert.Equal(t, "Firefox", export.Sessions[0].UserAgent)
+}
+
+func TestAccountData_Delete(t *testing.T) {
+	db, auth, svc, _ := newAccountService(t)
+	user, owned, shared := seedAccountData(t, db, auth)
+
+	// Attempts typed before the account existed only carry the identifier
+	for _, identifier := range []string{"rotate@example.com", "rotate", "someone-else"} {
+		require.NoError(t, db.Create(&models.LoginAttempt{Identifier: identifier, IPAddress: "192.0.2.1"}).Error)
+	}
+
+	assert.ErrorIs(t, svc.DeleteAccount(user.ID, "wrong-password"), services.ErrIncorrectPassword)
+	require.NoError(t, svc.DeleteAccount(user.ID, "password123"))
+
+	var count int64
+	require.NoError(t, db.Unscoped().Model(&models.User{}).Where("id = ?", user.ID).Count(&count).Error)
+	assert.Zero(t, count)
+	require.NoError(t, db.Unscoped().Model(&sqliteWatchlist{}).Where("user_id = ?", user.ID).Count(&count).Error)
+	assert.Zero(t, count)
+
+	for _, table := range []string{"sessions", "watchlist_members", "login_attempts"} {
+		require.NoError(t, db.Table(table).Where("user_id = ?", user.ID).Count(&count).Error)
+		assert.Zero(t, count, table)
+	}
+
+	var identifiers []string
+	require.NoError(t, db.Model(&models.LoginAttempt{}).Pluck("identifier", &identifiers).Error)
+	assert.Equal(t, []string{"someone-else"}, identifiers)
+
+	// The channel only the deleted user followed is collected, the shared one stays
+	assert.ErrorIs(t, db.First(&models.Channel{}, owned.ID).Error, gorm.ErrRecordNotFound)
+	assert.NoError(t, db.First(&models.Channel{}, shared.ID).Error)
+
+	_, err := auth.FindByIdentifier("rotate")
+	assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+}
+
+func TestAccountData_DeleteIdentityOnlyAccount(t *testing.T) {
+	db, auth, svc, _ := newAccountService(t)
+
+	_, _, err := auth.LoginWithIdentity(googleIdentity("g-1", "jane@example.com"), services.ClientInfo{})
+	require.NoError(t, err)
+	user, err := auth.FindByIdentifier("jane@example.com")
+	require.NoError(t, err)
+	require.Empty(t, user.PasswordHash)
+
+	// Without a password only a recent provider sign-in confirms the deletion
+	require.NoError(t, db.Model(&models.UserIdentity{}).Where("user_id = ?", user.ID).
+		Update("last_login_at", time.Now().Add(-time.Hour)).Error)
+	assert.ErrorIs(t, svc.DeleteAccount(user.ID, ""), services.ErrReauthenticationRequired)
+	assert.ErrorIs(t, svc.DeleteAccount(user.ID, "anything"), services.ErrReauthenticationRequired)
+
+	_, _, err = auth.LoginWithIdentity(googleIdentity("g-1", "jane@example.com"), services.ClientInfo{})
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteAccount(user.ID, ""))
+
+	var count int64
+	require.NoError(t, db.Model(&models.UserIdentity{}).Where("user_id = ?", user.ID).Count(&count).Error)
+	assert.Zero(t, count)
+	_, err = auth.FindByIdentifier("jane@example.com")
+	assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+}
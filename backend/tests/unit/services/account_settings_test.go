@@ -0,0 +1,144 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"bytecast/internal/services"
+)
+
+func TestAccountSettings_ChangePasswordKeepsCurrentSession(t *testing.T) {
+	_, auth, svc, _ := newAccountService(t)
+
+	current, _, err := auth.LoginUser("rotate", "password123", services.ClientInfo{UserAgent: "current"})
+	require.NoError(t, err)
+	other, _, err := auth.LoginUser("rotate", "password123", services.ClientInfo{UserAgent: "other"})
+	require.NoError(t, err)
+
+	user, err := auth.FindByIdentifier("rotate")
+	require.NoError(t, err)
+	sessions, err := auth.GetSessions(user.ID)
+	require.NoError(t, err)
+	var currentID string
+	for _, session := range sessions {
+		if session.UserAgent == "current" {
+			currentID = session.ID
+		}
+	}
+
+	assert.ErrorIs(t, svc.ChangePassword(user.ID, currentID, "wrong-password", "newpassword1"), services.ErrIncorrectPassword)
+	require.NoError(t, svc.ChangePassword(user.ID, currentID, "password123", "newpassword1"))
+
+	_, _, err = auth.RefreshTokens(other.RefreshToken, services.ClientInfo{})
+	assert.ErrorIs(t, err, services.ErrTokenRevoked)
+	_, _, err = auth.RefreshTokens(current.RefreshToken, services.ClientInfo{})
+	assert.NoError(t, err)
+
+	version, err := auth.TokenVersion(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), version)
+
+	_, _, err = auth.LoginUser("rotate", "newpassword1", services.ClientInfo{})
+	assert.NoError(t, err)
+}
+
+func TestAccountSettings_ChangeEmailRequiresReverification(t *testing.T) {
+	db, auth, svc, mail := newAccountService(t)
+	seedUser(t, db, "taken")
+
+	user, err := auth.FindByIdentifier("rotate")
+	require.NoError(t, err)
+	require.NoError(t, svc.SendVerificationEmail(user.ID))
+	require.NoError(t, svc.VerifyEmail(mail.lastToken(t)))
+
+	assert.ErrorIs(t, svc.ChangeEmail(user.ID, "", "password123", "taken@example.com"), services.ErrUserExists)
+	assert.ErrorIs(t, svc.ChangeEmail(user.ID, "", "wrong-password", "new@example.com"), services.ErrIncorrectPassword)
+
+	sent := len(mail.sent)
+	require.NoError(t, svc.ChangeEmail(user.ID, "", "password123", "new@example.com"))
+	require.Len(t, mail.sent, sent+2)
+	assert.Equal(t, "rotate@example.com", mail.sent[sent].To)
+	assert.Equal(t, "new@example.com", mail.sent[sent+1].To)
+
+	changed, err := auth.GetUserByID(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "new@example.com", changed.Email)
+	assert.Nil(t, changed.EmailVerifiedAt)
+
+	require.NoError(t, svc.VerifyEmail(mail.lastToken(t)))
+	changed, err = auth.GetUserByID(user.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, changed.EmailVerifiedAt)
+}
+
+func TestAccountSettings_ChangeEmailRace(t *testing.T) {
+	db, auth, svc, mail := newAccountService(t)
+	user, err := auth.FindByIdentifier("rotate")
+	require.NoError(t, err)
+
+	// Another account claims the address between the existence check and the update
+	require.NoError(t, db.Callback().Update().Before("gorm:update").Register("test:race", func(tx *gorm.DB) {
+		tx.Session(&gorm.Session{NewDB: true}).Exec(
+			"INSERT INTO users (email, username, password_hash) VALUES (?, ?, ?)",
+			"contested@example.com", "racer", "hashedpassword",
+		)
+	}))
+
+	sent := len(mail.sent)
+	assert.ErrorIs(t, svc.ChangeEmail(user.ID, "", "password123", "contested@example.com"), services.ErrUserExists)
+	assert.Len(t, mail.sent, sent)
+}
+
+func TestAccountSettings_IdentityOnlyAccountSetsPassword(t *testing.T) {
+	_, auth, svc, _ := newAccountService(t)
+
+	_, _, err := auth.LoginWithIdentity(googleIdentity("g-1", "jane@example.com"), services.ClientInfo{})
+	require.NoError(t, err)
+	user, err := auth.FindByIdentifier("jane@example.com")
+	require.NoError(t, err)
+
+	require.NoError(t, svc.ChangePassword(user.ID, "", "", "newpassword1"))
+	_, _, err = auth.LoginUser("jane@example.com", "newpassword1", services.ClientInfo{})
+	require.NoError(t, err)
+
+	// From now on the password is required
+	assert.ErrorIs(t, svc.ChangeEmail(user.ID, "", "", "jane@example.org"), services.ErrIncorrectPassword)
+}
+
+func TestAccountSettings_ChangeUsername(t *testing.T) {
+	db, auth, svc, _ := newAccountService(t)
+	seedUser(t, db, "taken")
+
+	user, err := auth.FindByIdentifier("rotate")
+	require.NoError(t, err)
+
+	_, err = svc.ChangeUsername(user.ID, "taken")
+	assert.ErrorIs(t, err, services.ErrUsernameTaken)
+
+	renamed, err := svc.ChangeUsername(user.ID, "renamed")
+	require.NoError(t, err)
+	assert.Equal(t, "renamed", renamed.Username)
+
+	_, _, err = auth.LoginUser("renamed", "password123", services.ClientInfo{})
+	assert.NoError(t, err)
+}
+
+func TestAccountSettings_ChangeUsernameRace(t *testing.T) {
+	db, auth, svc, _ := newAccountService(t)
+	user, err := auth.FindByIdentifier("rotate")
+	require.NoError(t, err)
+
+	// Another rename claims the name between the existence check and the update
+	require.NoError(t, db.Callback().Update().Before("gorm:update").Register("test:race", func(tx *gorm.DB) {
+		tx.Session(&gorm.Session{NewDB: true}).Exec(
+			"INSERT INTO users (email, username, password_hash) VALUES (?, ?, ?)",
+			"racer@example.com", "contested", "hashedpassword",
+		)
+	}))
+
+	_, err = svc.ChangeUsername(user.ID, "contested")
+	assert.ErrorIs(t, err, services.ErrUsernameTaken)
+}
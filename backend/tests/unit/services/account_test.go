@@ -0,0 +1,126 @@
+package services_test
+
+import (
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"bytecast/internal/mailer"
+	"bytecast/internal/models"
+	"bytecast/internal/services"
+)
+
+// recordingMailer keeps sent messages in memory
+type recordingMailer struct {
+	sent []mailer.Message
+}
+
+func (m *recordingMailer) Send(msg mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+var tokenPattern = regexp.MustCompile(`token=(\S+)`)
+
+// lastToken extracts the token from the link in the most recent email
+func (m *recordingMailer) lastToken(t *testing.T) string {
+	require.NotEmpty(t, m.sent)
+	match := tokenPattern.FindStringSubmatch(m.sent[len(m.sent)-1].Body)
+	require.Len(t, match, 2)
+	token, err := url.QueryUnescape(match[1])
+	require.NoError(t, err)
+	return token
+}
+
+func newAccountService(t *testing.T) (*gorm.DB, *services.AuthService, *services.AccountService, *recordingMailer) {
+	db, auth := newRotationAuthService(t)
+	mail := &recordingMailer{}
+	watchlists := services.NewWatchlistService(db, nil, nil)
+	watchlists.SetChannelGC(services.NewChannelGCService(db))
+	return db, auth, services.NewAccountService(db, auth, watchlists, mail, "http://localhost:4200/"), mail
+}
+
+func TestAccount_PasswordReset(t *testing.T) {
+	_, auth, svc, mail := newAccountService(t)
+
+	require.NoError(t, svc.RequestPasswordReset("nobody@example.com"))
+	assert.Empty(t, mail.sent)
+
+	session, _, err := auth.LoginUser("rotate", "password123", services.ClientInfo{})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.RequestPasswordReset("rotate@example.com"))
+	require.Len(t, mail.sent, 1)
+	assert.Equal(t, "rotate@example.com", mail.sent[0].To)
+	assert.Contains(t, mail.sent[0].Body, "http://localhost:4200/reset-password?token=")
+	token := mail.lastToken(t)
+
+	userID, err := svc.ResetPassword(token, "newpassword1")
+	require.NoError(t, err)
+	assert.Equal(t, rotateUserID(t, auth), userID)
+	_, err = svc.ResetPassword(token, "newpassword2")
+	assert.ErrorIs(t, err, services.ErrInvalidUserToken)
+
+	_, _, err = auth.RefreshTokens(session.RefreshToken, services.ClientInfo{})
+	assert.ErrorIs(t, err, services.ErrTokenRevoked)
+
+	_, _, err = auth.LoginUser("rotate", "password123", services.ClientInfo{})
+	assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+	_, _, err = auth.LoginUser("rotate", "newpassword1", services.ClientInfo{})
+	assert.NoError(t, err)
+
+	user, err := auth.FindByIdentifier("rotate")
+	require.NoError(t, err)
+	assert.NotNil(t, user.EmailVerifiedAt)
+}
+
+func TestAccount_PasswordResetTokenLifetime(t *testing.T) {
+	db, _, svc, mail := newAccountService(t)
+
+	require.NoError(t, svc.RequestPasswordReset("rotate@example.com"))
+	first := mail.lastToken(t)
+	require.NoError(t, svc.RequestPasswordReset("rotate@example.com"))
+	second := mail.lastToken(t)
+
+	// Requesting a new link invalidates the previous one
+	_, err := svc.ResetPassword(first, "newpassword1")
+	assert.ErrorIs(t, err, services.ErrInvalidUserToken)
+
+	require.NoError(t, db.Model(&models.UserToken{}).Where("used_at IS NULL").
+		Update("expires_at", time.Now().Add(-time.Minute)).Error)
+	_, err = svc.ResetPassword(second, "newpassword1")
+	assert.ErrorIs(t, err, services.ErrInvalidUserToken)
+}
+
+func TestAccount_EmailVerification(t *testing.T) {
+	db, auth, svc, mail := newAccountService(t)
+
+	user, err := auth.FindByIdentifier("rotate")
+	require.NoError(t, err)
+
+	require.NoError(t, svc.SendVerificationEmail(user.ID))
+	assert.Contains(t, mail.sent[0].Body, "http://localhost:4200/verify-email?token=")
+	token := mail.lastToken(t)
+
+	assert.ErrorIs(t, svc.VerifyEmail("bogus"), services.ErrInvalidUserToken)
+	require.NoError(t, svc.VerifyEmail(token))
+	assert.ErrorIs(t, svc.VerifyEmail(token), services.ErrInvalidUserToken)
+
+	verified, err := auth.GetUserByID(user.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, verified.EmailVerifiedAt)
+	assert.ErrorIs(t, svc.SendVerificationEmail(user.ID), services.ErrEmailAlreadyVerified)
+
+	// A link sent to a previous address no longer verifies the account
+	require.NoError(t, db.Model(&models.User{}).Where("id = ?", user.ID).
+		Updates(map[string]interface{}{"email_verified_at": nil}).Error)
+	require.NoError(t, svc.SendVerificationEmail(user.ID))
+	stale := mail.lastToken(t)
+	require.NoError(t, db.Model(&models.User{}).Where("id = ?", user.ID).Update("email", "new@example.com").Error)
+	assert.ErrorIs(t, svc.VerifyEmail(stale), services.ErrInvalidUserToken)
+}
@@ -0,0 +1,161 @@
+package services_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"bytecast/api/middleware"
+	"bytecast/internal/models"
+	"bytecast/internal/services"
+)
+
+func newAdminService(t *testing.T) (*gorm.DB, *services.AuthService, *services.AdminService, *models.User) {
+	db, auth := newRotationAuthService(t)
+	admin := seedUser(t, db, "admin")
+	require.NoError(t, db.Model(admin).Update("role", models.RoleAdmin).Error)
+	return db, auth, services.NewAdminService(db, auth), admin
+}
+
+func TestAdmin_ListUsers(t *testing.T) {
+	db, _, svc, _ := newAdminService(t)
+	seedUser(t, db, "alice")
+	seedUser(t, db, "bob")
+
+	page, err := svc.ListUsers("", 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), page.Total)
+	require.Len(t, page.Users, 2)
+	assert.Equal(t, "rotate", page.Users[0].Username)
+
+	page, err = svc.ListUsers("", 2, 2)
+	require.NoError(t, err)
+	require.Len(t, page.Users, 2)
+	assert.Equal(t, "bob", page.Users[1].Username)
+
+	page, err = svc.ListUsers(" ALI ", 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), page.Total)
+	require.Len(t, page.Users, 1)
+	assert.Equal(t, "alice", page.Users[0].Username)
+}
+
+func TestAdmin_DisableUser(t *testing.T) {
+	_, auth, svc, admin := newAdminService(t)
+	engine := newPersonalTokenEngine(t, auth, auth)
+	userID := rotateUserID(t, auth)
+
+	session, _, err := auth.LoginUser("rotate", "password123", services.ClientInfo{})
+	require.NoError(t, err)
+	_, pat, err := auth.CreatePersonalToken(userID, "script", models.TokenScopeWrite, nil)
+	require.NoError(t, err)
+
+	user, err := svc.SetUserDisabled(admin.ID, userID, true)
+	require.NoError(t, err)
+	assert.NotNil(t, user.DisabledAt)
+
+	// Every credential stops working at once
+	assert.Equal(t, http.StatusUnauthorized, requestWithToken(engine, http.MethodGet, session.AccessToken))
+	assert.Equal(t, http.StatusForbidden, requestWithToken(engine, http.MethodGet, pat))
+	_, _, err = auth.RefreshTokens(session.RefreshToken, services.ClientInfo{})
+	assert.ErrorIs(t, err, services.ErrTokenRevoked)
+	_, _, err = auth.LoginUser("rotate", "password123", services.ClientInfo{})
+	assert.ErrorIs(t, err, services.ErrAccountDisabled)
+	_, _, err = auth.LoginWithIdentity(googleIdentity("g-1", "rotate@example.com"), services.ClientInfo{})
+	assert.ErrorIs(t, err, services.ErrAccountDisabled)
+
+	user, err = svc.SetUserDisabled(admin.ID, userID, false)
+	require.NoError(t, err)
+	assert.Nil(t, user.DisabledAt)
+
+	_, _, err = auth.LoginUser("rotate", "password123", services.ClientInfo{})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, requestWithToken(engine, http.MethodGet, pat))
+}
+
+func TestAdmin_DisableUserErrors(t *testing.T) {
+	_, _, svc, admin := newAdminService(t)
+
+	_, err := svc.SetUserDisabled(admin.ID, admin.ID, true)
+	assert.ErrorIs(t, err, services.ErrCannotDisableSelf)
+
+	_, err = svc.SetUserDisabled(admin.ID, 9999, true)
+	assert.ErrorIs(t, err, services.ErrUserNotFound)
+}
+
+func TestAdmin_GetWatchlist(t *testing.T) {
+	db, _, svc, _ := newAdminService(t)
+	owner := seedUser(t, db, "owner")
+	watchlist := seedWatchlist(t, db, owner.ID, "Private")
+	seedChannel(t, db, watchlist.ID, "UC1")
+	require.NoError(t, db.Delete(watchlist).Error)
+
+	got, err := svc.GetWatchlist(watchlist.ID)
+	require.NoError(t, err)
+	assert.Equal(t, owner.ID, got.UserID)
+	assert.True(t, got.DeletedAt.Valid)
+	require.Len(t, got.Channels, 1)
+	assert.Equal(t, "UC1", got.Channels[0].YoutubeID)
+
+	_, err = svc.GetWatchlist(9999)
+	assert.ErrorIs(t, err, services.ErrWatchlistNotFound)
+}
+
+func TestAdmin_ResubscribeChannels(t *testing.T) {
+	db, _, svc, admin := newAdminService(t)
+	watchlist := seedWatchlist(t, db, admin.ID, "Subscriptions")
+	seedChannel(t, db, watchlist.ID, "UC1")
+	seedChannel(t, db, watchlist.ID, "UC2")
+
+	assert.ErrorIs(t, svc.ResubscribeChannel("UC1"), services.ErrPubSubDisabled)
+
+	pubsub := &recordingPubSub{}
+	svc.SetPubSubService(pubsub)
+
+	require.NoError(t, svc.ResubscribeChannel("UC1"))
+	assert.ErrorIs(t, svc.ResubscribeChannel("UC404"), services.ErrChannelNotFound)
+
+	count, err := svc.ResubscribeAllChannels()
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.ElementsMatch(t, []string{"UC1", "UC1", "UC2"}, pubsub.subscribed)
+}
+
+func TestRequireRole(t *testing.T) {
+	db, auth, _, admin := newAdminService(t)
+	userID := rotateUserID(t, auth)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/admin", func(c *gin.Context) {
+		var id uint
+		if c.GetHeader("X-User") == "admin" {
+			id = admin.ID
+		} else {
+			id = userID
+		}
+		c.Set("user_id", id)
+	}, middleware.RequireRole(auth, models.RoleAdmin), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func(user string) int {
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		req.Header.Set("X-User", user)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request("admin"))
+	assert.Equal(t, http.StatusForbidden, request("rotate"))
+
+	// Demotion applies to the very next request
+	require.NoError(t, db.Model(admin).Update("role", models.RoleUser).Error)
+	assert.Equal(t, http.StatusForbidden, request("admin"))
+}
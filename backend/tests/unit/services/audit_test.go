@@ -0,0 +1,195 @@
+package services_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"bytecast/api/handler"
+	"bytecast/api/middleware"
+	"bytecast/configs"
+	"bytecast/internal/models"
+	"bytecast/internal/services"
+)
+
+func newAuditedAuthEngine(t *testing.T) (*gin.Engine, *gorm.DB, *services.AuthService) {
+	db, auth, account, _ := newAccountService(t)
+	audit := services.NewAuditService(db)
+	cfg := &configs.Config{Server: configs.Server{Environment: "development", Domain: "localhost"}}
+	authMiddleware := middleware.AuthMiddleware(newTestTokenManager(t), auth, nil)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(middleware.ErrorHandler(), middleware.RequestID())
+	handler.NewAuthHandler(auth, account, audit, cfg).RegisterRoutes(engine, authMiddleware, nil)
+	handler.NewAccountHandler(account, audit, cfg).RegisterRoutes(engine, authMiddleware, nil)
+	return engine, db, auth
+}
+
+func postLogin(engine *gin.Engine, identifier, password string) *httptest.ResponseRecorder {
+	body := strings.NewReader(`{"identifier":"` + identifier + `","password":"` + password + `"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "audit-test")
+	req.Header.Set("X-Request-ID", "req-"+password)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func auditEvents(t *testing.T, db *gorm.DB) []models.AuditEvent {
+	var events []models.AuditEvent
+	require.NoError(t, db.Order("id").Find(&events).Error)
+	return events
+}
+
+func TestAudit_RecordsLogins(t *testing.T) {
+	engine, db, auth := newAuditedAuthEngine(t)
+	userID := rotateUserID(t, auth)
+
+	require.Equal(t, http.StatusUnauthorized, postLogin(engine, "rotate", "wrongpass").Code)
+	require.Equal(t, http.StatusUnauthorized, postLogin(engine, "nobody", "wrongpass").Code)
+	require.Equal(t, http.StatusOK, postLogin(engine, "rotate", "password123").Code)
+
+	events := auditEvents(t, db)
+	require.Len(t, events, 3)
+
+	assert.Equal(t, services.AuditLoginFailed, events[0].Action)
+	require.NotNil(t, events[0].UserID)
+	assert.Equal(t, userID, *events[0].UserID)
+	assert.Nil(t, events[0].ActorID)
+	assert.Equal(t, "rotate", events[0].Detail)
+	assert.Equal(t, "req-wrongpass", events[0].RequestID)
+	assert.Equal(t, "audit-test", events[0].UserAgent)
+	assert.NotEmpty(t, events[0].IPAddress)
+
+	// Attempts on unknown accounts are kept, without a user
+	assert.Equal(t, services.AuditLoginFailed, events[1].Action)
+	assert.Nil(t, events[1].UserID)
+	assert.Equal(t, "nobody", events[1].Detail)
+
+	assert.Equal(t, services.AuditLogin, events[2].Action)
+	require.NotNil(t, events[2].ActorID)
+	assert.Equal(t, userID, *events[2].ActorID)
+	assert.Equal(t, "req-password123", events[2].RequestID)
+}
+
+func TestAudit_RecordsRefreshAndLogout(t *testing.T) {
+	engine, db, auth := newAuditedAuthEngine(t)
+	userID := rotateUserID(t, auth)
+	refresh, csrf := loginCookies(t, engine)
+
+	w := cookieRequest(engine, "/api/v1/auth/refresh", refresh, csrf.Value)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// Replaying the rotated token is recorded against the account
+	w = cookieRequest(engine, "/api/v1/auth/refresh", refresh, csrf.Value)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	refresh, csrf = loginCookies(t, engine)
+	w = cookieRequest(engine, "/api/v1/auth/logout", refresh, csrf.Value)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var actions []string
+	for _, event := range auditEvents(t, db) {
+		require.NotNil(t, event.UserID)
+		assert.Equal(t, userID, *event.UserID)
+		actions = append(actions, event.Action)
+	}
+	assert.Equal(t, []string{
+		services.AuditLogin,
+		services.AuditTokenRefreshed,
+		services.AuditTokenReused,
+		services.AuditLogin,
+		services.AuditLogout,
+	}, actions)
+}
+
+func TestAudit_ListEvents(t *testing.T) {
+	db, auth := newRotationAuthService(t)
+	audit := services.NewAuditService(db)
+	userID := rotateUserID(t, auth)
+	other := seedUser(t, db, "other")
+
+	for i := 0; i < 3; i++ {
+		audit.Record(services.AuditEntry{UserID: userID, ActorID: userID, Action: services.AuditWatchlistCreated})
+	}
+	audit.Record(services.AuditEntry{UserID: userID, ActorID: userID, Action: services.AuditPasswordChanged})
+	audit.Record(services.AuditEntry{UserID: other.ID, ActorID: other.ID, Action: services.AuditWatchlistCreated})
+
+	page, err := audit.ListEvents(services.AuditFilter{UserID: userID}, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), page.Total)
+	require.Len(t, page.Events, 2)
+	assert.Equal(t, services.AuditPasswordChanged, page.Events[0].Action)
+
+	page, err = audit.ListEvents(services.AuditFilter{Action: services.AuditWatchlistCreated}, 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), page.Total)
+	assert.Equal(t, 100, page.PageSize)
+
+	page, err = audit.ListEvents(services.AuditFilter{}, 3, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), page.Total)
+	require.Len(t, page.Events, 1)
+	require.NotNil(t, page.Events[0].UserID)
+	assert.Equal(t, userID, *page.Events[0].UserID, "the last page holds the oldest event")
+}
+
+func TestAudit_LongFieldsAreTruncated(t *testing.T) {
+	db, _ := newRotationAuthService(t)
+	audit := services.NewAuditService(db)
+
+	audit.Record(services.AuditEntry{
+		Action: services.AuditLoginFailed,
+		Detail: strings.Repeat("é", 300),
+		Client: services.ClientInfo{UserAgent: strings.Repeat("a", 600)},
+	})
+
+	events := auditEvents(t, db)
+	require.Len(t, events, 1)
+	assert.Equal(t, strings.Repeat("é", 255), events[0].Detail)
+	assert.Len(t, events[0].UserAgent, 512)
+}
+
+func TestAudit_NilServiceRecordsNothing(t *testing.T) {
+	var audit *services.AuditService
+	assert.NotPanics(t, func() {
+		audit.Record(services.AuditEntry{Action: services.AuditLogin})
+	})
+}
+
+func TestAudit_UserSeesOwnEvents(t *testing.T) {
+	engine, db, _ := newAuditedAuthEngine(t)
+	other := seedUser(t, db, "other")
+	services.NewAuditService(db).Record(services.AuditEntry{UserID: other.ID, Action: services.AuditPasswordChanged})
+
+	w := postLogin(engine, "rotate", "password123")
+	require.Equal(t, http.StatusOK, w.Code)
+	var login struct {
+		AccessToken string `json:"access_token"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &login))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/me/audit?page_size=10", nil)
+	req.Header.Set("Authorization", "Bearer "+login.AccessToken)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var page struct {
+		Events []models.AuditEvent `json:"events"`
+		Total  int64               `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, int64(1), page.Total)
+	require.Len(t, page.Events, 1)
+	assert.Equal(t, services.AuditLogin, page.Events[0].Action)
+}
@@ -0,0 +1,118 @@
+package services_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bytecast/api/handler"
+	"bytecast/api/middleware"
+	"bytecast/api/utils"
+	"bytecast/configs"
+)
+
+func newAuthEngine(t *testing.T) *gin.Engine {
+	_, auth, account, _ := newAccountService(t)
+	cfg := &configs.Config{Server: configs.Server{Environment: "development", Domain: "localhost"}}
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(middleware.ErrorHandler())
+	handler.NewAuthHandler(auth, account, nil, cfg).
+		RegisterRoutes(engine, middleware.AuthMiddleware(newTestTokenManager(t), auth, nil), nil)
+	return engine
+}
+
+// loginCookies logs in over HTTP and returns the refresh and CSRF cookies
+func loginCookies(t *testing.T, engine *gin.Engine) (refresh, csrf *http.Cookie) {
+	body := strings.NewReader(`{"identifier":"rotate","password":"password123"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", body)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	for _, cookie := range w.Result().Cookies() {
+		switch cookie.Name {
+		case "refresh_token":
+			refresh = cookie
+		case utils.CSRFCookieName:
+			csrf = cookie
+		}
+	}
+	require.NotNil(t, refresh)
+	require.NotNil(t, csrf)
+	return refresh, csrf
+}
+
+func cookieRequest(engine *gin.Engine, path string, refresh *http.Cookie, csrfHeader string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, nil)
+	req.AddCookie(refresh)
+	if csrfHeader != "" {
+		req.Header.Set(utils.CSRFHeaderName, csrfHeader)
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestCSRF_CookieIsReadableAndBound(t *testing.T) {
+	engine := newAuthEngine(t)
+	refresh, csrf := loginCookies(t, engine)
+
+	assert.True(t, refresh.HttpOnly)
+	assert.False(t, csrf.HttpOnly)
+	assert.Equal(t, utils.CSRFToken(refresh.Value), csrf.Value)
+}
+
+func TestCSRF_RefreshRequiresHeader(t *testing.T) {
+	engine := newAuthEngine(t)
+	refresh, csrf := loginCookies(t, engine)
+
+	// A cross-site form post carries the cookie but cannot add the header
+	w := cookieRequest(engine, "/api/v1/auth/refresh", refresh, "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = cookieRequest(engine, "/api/v1/auth/refresh", refresh, "forged")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// The rejected attempts must not have rotated the token
+	w = cookieRequest(engine, "/api/v1/auth/refresh", refresh, csrf.Value)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// The rotated refresh token comes with a new CSRF token
+	var rotated *http.Cookie
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == utils.CSRFCookieName {
+			rotated = cookie
+		}
+	}
+	require.NotNil(t, rotated)
+	assert.NotEqual(t, csrf.Value, rotated.Value)
+}
+
+func TestCSRF_TokenFromAnotherSessionIsRejected(t *testing.T) {
+	engine := newAuthEngine(t)
+	victim, _ := loginCookies(t, engine)
+	_, attackerCSRF := loginCookies(t, engine)
+
+	w := cookieRequest(engine, "/api/v1/auth/refresh", victim, attackerCSRF.Value)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestCSRF_LogoutRequiresHeader(t *testing.T) {
+	engine := newAuthEngine(t)
+	refresh, csrf := loginCookies(t, engine)
+
+	w := cookieRequest(engine, "/api/v1/auth/logout", refresh, "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// The session survived the forged logout
+	w = cookieRequest(engine, "/api/v1/auth/logout", refresh, csrf.Value)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,138 @@
+package services_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bytecast/internal/models"
+	"bytecast/internal/services"
+)
+
+func googleIdentity(subject, email string) services.ExternalIdentity {
+	return services.ExternalIdentity{
+		Provider:      "google",
+		Subject:       subject,
+		Email:         email,
+		EmailVerified: true,
+	}
+}
+
+func TestLoginWithIdentity_CreatesAccount(t *testing.T) {
+	db, svc := newRotationAuthService(t)
+
+	tokens, _, err := svc.LoginWithIdentity(googleIdentity("g-1", "jane.doe+yt@example.com"), services.ClientInfo{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+
+	user, err := svc.FindByIdentifier("jane.doe+yt@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "janedoeyt", user.Username)
+	assert.NotNil(t, user.EmailVerifiedAt)
+	assert.Empty(t, user.PasswordHash)
+
+	var watchlists int64
+	require.NoError(t, db.Model(&sqliteWatchlist{}).Where("user_id = ?", user.ID).Count(&watchlists).Error)
+	assert.Equal(t, int64(1), watchlists)
+
+	// Signing in again finds the same account by subject, even after an email change at the provider
+	_, _, err = svc.LoginWithIdentity(googleIdentity("g-1", "jane@example.org"), services.ClientInfo{})
+	require.NoError(t, err)
+
+	var identities []models.UserIdentity
+	require.NoError(t, db.Find(&identities).Error)
+	require.Len(t, identities, 1)
+	assert.Equal(t, user.ID, identities[0].UserID)
+	assert.Equal(t, "jane@example.org", identities[0].Email)
+
+	var users int64
+	require.NoError(t, db.Model(&models.User{}).Count(&users).Error)
+	assert.Equal(t, int64(2), users)
+}
+
+func TestLoginWithIdentity_AvoidsTakenUsernames(t *testing.T) {
+	_, svc := newRotationAuthService(t)
+
+	_, _, err := svc.LoginWithIdentity(googleIdentity("g-1", "rotate@gmail.com"), services.ClientInfo{})
+	require.NoError(t, err)
+
+	user, err := svc.FindByIdentifier("rotate@gmail.com")
+	require.NoError(t, err)
+	assert.Regexp(t, `^rotate\d{4}$`, user.Username)
+}
+
+func TestLoginWithIdentity_LinksVerifiedAccount(t *testing.T) {
+	db, svc := newRotationAuthService(t)
+	require.NoError(t, db.Model(&models.User{}).Where("username = ?", "rotate").
+		Update("email_verified_at", time.Now()).Error)
+
+	session, _, err := svc.LoginUser("rotate", "password123", services.ClientInfo{})
+	require.NoError(t, err)
+
+	_, _, err = svc.LoginWithIdentity(googleIdentity("g-1", "Rotate@Example.com"), services.ClientInfo{})
+	require.NoError(t, err)
+
+	var identity models.UserIdentity
+	require.NoError(t, db.First(&identity).Error)
+	user, err := svc.FindByIdentifier("rotate")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, identity.UserID)
+
+	// The password and existing sessions keep working
+	_, _, err = svc.LoginUser("rotate", "password123", services.ClientInfo{})
+	assert.NoError(t, err)
+	_, _, err = svc.RefreshTokens(session.RefreshToken, services.ClientInfo{})
+	assert.NoError(t, err)
+}
+
+func TestLoginWithIdentity_TakesOverUnverifiedAccount(t *testing.T) {
+	_, svc := newRotationAuthService(t)
+
+	session, _, err := svc.LoginUser("rotate", "password123", services.ClientInfo{})
+	require.NoError(t, err)
+
+	_, _, err = svc.LoginWithIdentity(googleIdentity("g-1", "rotate@example.com"), services.ClientInfo{})
+	require.NoError(t, err)
+
+	// Whoever registered the address without verifying it loses access
+	_, _, err = svc.RefreshTokens(session.RefreshToken, services.ClientInfo{})
+	assert.ErrorIs(t, err, services.ErrTokenRevoked)
+	_, _, err = svc.LoginUser("rotate", "password123", services.ClientInfo{})
+	assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+
+	user, err := svc.FindByIdentifier("rotate")
+	require.NoError(t, err)
+	assert.NotNil(t, user.EmailVerifiedAt)
+}
+
+func TestLoginWithIdentity_RequiresVerifiedEmail(t *testing.T) {
+	db, svc := newRotationAuthService(t)
+
+	identity := googleIdentity("g-1", "rotate@example.com")
+	identity.EmailVerified = false
+	_, _, err := svc.LoginWithIdentity(identity, services.ClientInfo{})
+	assert.ErrorIs(t, err, services.ErrIdentityEmailUnverified)
+
+	_, _, err = svc.LoginWithIdentity(googleIdentity("g-2", ""), services.ClientInfo{})
+	assert.ErrorIs(t, err, services.ErrIdentityEmailUnverified)
+
+	var identities int64
+	require.NoError(t, db.Model(&models.UserIdentity{}).Count(&identities).Error)
+	assert.Zero(t, identities)
+}
+
+func TestLoginWithIdentity_TwoFactorChallenge(t *testing.T) {
+	_, auth, svc, _ := newAccountService(t)
+	secret, _ := enrollTOTP(t, auth, svc)
+
+	_, _, err := auth.LoginWithIdentity(googleIdentity("g-1", "rotate@example.com"), services.ClientInfo{})
+	var challenge *services.TwoFactorRequiredError
+	require.True(t, errors.As(err, &challenge))
+
+	_, _, user, err := auth.CompleteTwoFactorLogin(challenge.ChallengeToken, nextCode(t, secret), services.ClientInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, "rotate", user.Username)
+}
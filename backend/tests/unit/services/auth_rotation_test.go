@@ -0,0 +1,93 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"bytecast/internal/models"
+	"bytecast/internal/services"
+	"bytecast/internal/token"
+)
+
+func newTestTokenManager(t *testing.T) *token.Manager {
+	keys, err := token.NewKeySet(token.NewHMACKey([]byte("test-secret")))
+	require.NoError(t, err)
+	return token.NewManager(keys, "bytecast", "bytecast-api", 30*time.Second)
+}
+
+func newRotationAuthService(t *testing.T) (*gorm.DB, *services.AuthService) {
+	db := newSQLiteDB(t)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := &models.User{Email: "rotate@example.com", Username: "rotate", PasswordHash: string(hash)}
+	require.NoError(t, db.Create(user).Error)
+
+	return db, services.NewAuthService(db, services.NewWatchlistService(db, nil, nil), newTestTokenManager(t))
+}
+
+func TestRefreshTokens_RotatesWithinFamily(t *testing.T) {
+	db, svc := newRotationAuthService(t)
+
+	tokens, _, err := svc.LoginUser("rotate", "password123", services.ClientInfo{})
+	require.NoError(t, err)
+
+	rotated, _, err := svc.RefreshTokens(tokens.RefreshToken, services.ClientInfo{})
+	require.NoError(t, err)
+	assert.NotEqual(t, tokens.RefreshToken, rotated.RefreshToken)
+
+	revoked, err := svc.IsTokenRevoked(tokens.RefreshToken)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	_, _, err = svc.RefreshTokens(rotated.RefreshToken, services.ClientInfo{})
+	require.NoError(t, err)
+
+	var sessions int64
+	require.NoError(t, db.Model(&models.Session{}).Count(&sessions).Error)
+	assert.Equal(t, int64(1), sessions)
+}
+
+func TestRefreshTokens_ReuseRevokesFamily(t *testing.T) {
+	_, svc := newRotationAuthService(t)
+
+	tokens, _, err := svc.LoginUser("rotate", "password123", services.ClientInfo{})
+	require.NoError(t, err)
+	other, _, err := svc.LoginUser("rotate", "password123", services.ClientInfo{})
+	require.NoError(t, err)
+
+	rotated, _, err := svc.RefreshTokens(tokens.RefreshToken, services.ClientInfo{})
+	require.NoError(t, err)
+
+	_, _, err = svc.RefreshTokens(tokens.RefreshToken, services.ClientInfo{})
+	assert.ErrorIs(t, err, services.ErrTokenReused)
+
+	// The legitimate holder of the latest token is logged out too
+	_, _, err = svc.RefreshTokens(rotated.RefreshToken, services.ClientInfo{})
+	assert.ErrorIs(t, err, services.ErrTokenRevoked)
+
+	// Other sessions are unaffected
+	_, _, err = svc.RefreshTokens(other.RefreshToken, services.ClientInfo{})
+	assert.NoError(t, err)
+}
+
+func TestRevokeToken_EndsSession(t *testing.T) {
+	_, svc := newRotationAuthService(t)
+
+	tokens, _, err := svc.LoginUser("rotate", "password123", services.ClientInfo{})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.RevokeToken(tokens.RefreshToken))
+	assert.ErrorIs(t, svc.RevokeToken(tokens.RefreshToken), services.ErrTokenRevoked)
+
+	_, _, err = svc.RefreshTokens(tokens.RefreshToken, services.ClientInfo{})
+	assert.ErrorIs(t, err, services.ErrTokenRevoked)
+
+	_, _, err = svc.RefreshTokens(tokens.AccessToken, services.ClientInfo{})
+	assert.ErrorIs(t, err, services.ErrTokenInvalid)
+}
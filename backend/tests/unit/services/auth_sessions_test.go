@@ -0,0 +1,87 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bytecast/internal/services"
+)
+
+func TestSessions_ListAndRevoke(t *testing.T) {
+	db, svc := newRotationAuthService(t)
+	stranger := seedUser(t, db, "stranger")
+
+	laptop := services.ClientInfo{UserAgent: "Firefox", IPAddress: "10.0.0.1"}
+	phone := services.ClientInfo{UserAgent: "Safari", IPAddress: "10.0.0.2"}
+
+	laptopTokens, _, err := svc.LoginUser("rotate", "password123", laptop)
+	require.NoError(t, err)
+	_, _, err = svc.LoginUser("rotate", "password123", phone)
+	require.NoError(t, err)
+
+	user, err := svc.FindByIdentifier("rotate")
+	require.NoError(t, err)
+
+	sessions, err := svc.GetSessions(user.ID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+
+	var laptopID string
+	for _, session := range sessions {
+		if session.UserAgent == "Firefox" {
+			laptopID = session.ID
+			assert.Equal(t, "10.0.0.1", session.IPAddress)
+		}
+	}
+	require.NotEmpty(t, laptopID)
+
+	assert.ErrorIs(t, svc.RevokeSession(stranger.ID, laptopID), services.ErrSessionNotFound)
+	require.NoError(t, svc.RevokeSession(user.ID, laptopID))
+	assert.ErrorIs(t, svc.RevokeSession(user.ID, laptopID), services.ErrSessionNotFound)
+
+	_, _, err = svc.RefreshTokens(laptopTokens.RefreshToken, laptop)
+	assert.ErrorIs(t, err, services.ErrTokenRevoked)
+
+	sessions, err = svc.GetSessions(user.ID)
+	require.NoError(t, err)
+	assert.Len(t, sessions, 1)
+}
+
+func TestSessions_RefreshUpdatesClient(t *testing.T) {
+	_, svc := newRotationAuthService(t)
+
+	tokens, _, err := svc.LoginUser("rotate", "password123", services.ClientInfo{UserAgent: "Firefox", IPAddress: "10.0.0.1"})
+	require.NoError(t, err)
+	_, _, err = svc.RefreshTokens(tokens.RefreshToken, services.ClientInfo{UserAgent: "Firefox", IPAddress: "10.0.0.9"})
+	require.NoError(t, err)
+
+	user, err := svc.FindByIdentifier("rotate")
+	require.NoError(t, err)
+	sessions, err := svc.GetSessions(user.ID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "10.0.0.9", sessions[0].IPAddress)
+}
+
+func TestSessions_RevokeAll(t *testing.T) {
+	_, svc := newRotationAuthService(t)
+
+	first, _, err := svc.LoginUser("rotate", "password123", services.ClientInfo{})
+	require.NoError(t, err)
+	second, _, err := svc.LoginUser("rotate", "password123", services.ClientInfo{})
+	require.NoError(t, err)
+
+	user, err := svc.FindByIdentifier("rotate")
+	require.NoError(t, err)
+
+	revoked, err := svc.RevokeAllSessions(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), revoked)
+
+	for _, tokens := range []*services.TokenPair{first, second} {
+		_, _, err = svc.RefreshTokens(tokens.RefreshToken, services.ClientInfo{})
+		assert.ErrorIs(t, err, services.ErrTokenRevoked)
+	}
+}
@@ -4,33 +4,23 @@ import (
     "testing"
     "time"
 
-    "github.com/golang-jwt/jwt"
-    "gorm.io/driver/postgres"
+    "github.com/golang-jwt/jwt/v5"
     "gorm.io/gorm"
 
-    "bytecast/internal/models"
     "bytecast/internal/services"
 )
 
 func setupTestDB(t *testing.T) *gorm.DB {
-    dsn := "host=localhost user=postgres password=bytecast dbname=bytecast_test port=5433 sslmode=disable"
-    db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
-    if err != nil {
-        t.Fatalf("Failed to connect to test database: %v", err)
-    }
-
-    db.Exec("DROP TABLE IF EXISTS revoked_tokens, users CASCADE")
-
-    if err := db.AutoMigrate(&models.User{}, &models.RevokedToken{}); err != nil {
-        t.Fatalf("Failed to run migrations: %v", err)
-    }
+    return newSQLiteDB(t)
+}
 
-    return db
+func newTestAuthService(t *testing.T, db *gorm.DB) *services.AuthService {
+    return services.NewAuthService(db, services.NewWatchlistService(db, nil, nil), newTestTokenManager(t))
 }
 
 func TestAuthService_RegisterUser(t *testing.T) {
     db := setupTestDB(t)
-    authService := services.NewAuthService(db, "test-secret")
+    authService := newTestAuthService(t, db)
 
     tests := []struct {
         name     string
@@ -67,7 +57,7 @@ func TestAuthService_RegisterUser(t *testing.T) {
 
     for _, tt := range tests {
         t.Run(tt.name, func(t *testing.T) {
-            err := authService.RegisterUser(tt.email, tt.username, tt.password)
+            err := authService.RegisterUser(tt.email, tt.username, tt.password, "")
             if (err != nil) != tt.wantErr {
                 t.Errorf("RegisterUser() error = %v, wantErr %v", err, tt.wantErr)
             }
@@ -77,15 +67,15 @@ func TestAuthService_RegisterUser(t *testing.T) {
 
 func TestAuthService_RevokeToken(t *testing.T) {
     db := setupTestDB(t)
-    authService := services.NewAuthService(db, "test-secret")
+    authService := newTestAuthService(t, db)
 
     email := "test@example.com"
     password := "password123"
-    if err := authService.RegisterUser(email, "testuser", password); err != nil {
+    if err := authService.RegisterUser(email, "testuser", password, ""); err != nil {
         t.Fatalf("Failed to register test user: %v", err)
     }
 
-    tokens, _, err := authService.LoginUser(email, password)
+    tokens, _, err := authService.LoginUser(email, password, services.ClientInfo{})
     if err != nil {
         t.Fatalf("Failed to login test user: %v", err)
     }
@@ -121,7 +111,7 @@ func TestAuthService_RevokeToken(t *testing.T) {
             }
 
             if !tt.wantErr {
-                _, _, err := authService.RefreshTokens(tt.refreshToken)
+                _, _, err := authService.RefreshTokens(tt.refreshToken, services.ClientInfo{})
                 if err == nil {
                     t.Error("RefreshTokens() succeeded with revoked token")
                 }
@@ -132,15 +122,15 @@ func TestAuthService_RevokeToken(t *testing.T) {
 
 func TestAuthService_RefreshTokens_WithRevokedToken(t *testing.T) {
     db := setupTestDB(t)
-    authService := services.NewAuthService(db, "test-secret")
+    authService := newTestAuthService(t, db)
 
     email := "test@example.com"
     password := "password123"
-    if err := authService.RegisterUser(email, "testuser", password); err != nil {
+    if err := authService.RegisterUser(email, "testuser", password, ""); err != nil {
         t.Fatalf("Failed to register test user: %v", err)
     }
 
-    tokens, _, err := authService.LoginUser(email, password)
+    tokens, _, err := authService.LoginUser(email, password, services.ClientInfo{})
     if err != nil {
         t.Fatalf("Failed to login test user: %v", err)
     }
@@ -149,17 +139,17 @@ func TestAuthService_RefreshTokens_WithRevokedToken(t *testing.T) {
         t.Fatalf("Failed to revoke token: %v", err)
     }
 
-    _, _, err = authService.RefreshTokens(tokens.RefreshToken)
+    _, _, err = authService.RefreshTokens(tokens.RefreshToken, services.ClientInfo{})
     if err == nil {
         t.Error("RefreshTokens() succeeded with revoked token")
     }
 
-    newTokens, _, err := authService.LoginUser(email, password)
+    newTokens, _, err := authService.LoginUser(email, password, services.ClientInfo{})
     if err != nil {
         t.Fatalf("Failed to get new tokens: %v", err)
     }
 
-    refreshedTokens, _, err := authService.RefreshTokens(newTokens.RefreshToken)
+    refreshedTokens, _, err := authService.RefreshTokens(newTokens.RefreshToken, services.ClientInfo{})
     if err != nil {
         t.Errorf("RefreshTokens() failed with new token: %v", err)
     }
@@ -170,11 +160,11 @@ func TestAuthService_RefreshTokens_WithRevokedToken(t *testing.T) {
 
 func TestAuthService_LoginUser(t *testing.T) {
     db := setupTestDB(t)
-    authService := services.NewAuthService(db, "test-secret")
+    authService := newTestAuthService(t, db)
 
     email := "test@example.com"
     password := "password123"
-    if err := authService.RegisterUser(email, "testuser", password); err != nil {
+    if err := authService.RegisterUser(email, "testuser", password, ""); err != nil {
         t.Fatalf("Failed to register test user: %v", err)
     }
 
@@ -206,7 +196,7 @@ func TestAuthService_LoginUser(t *testing.T) {
 
     for _, tt := range tests {
         t.Run(tt.name, func(t *testing.T) {
-            tokens, exp, err := authService.LoginUser(tt.email, tt.password)
+            tokens, exp, err := authService.LoginUser(tt.email, tt.password, services.ClientInfo{})
             if (err != nil) != tt.wantErr {
                 t.Errorf("LoginUser() error = %v, wantErr %v", err, tt.wantErr)
                 return
@@ -246,15 +236,15 @@ func TestAuthService_LoginUser(t *testing.T) {
 
 func TestAuthService_RefreshTokens(t *testing.T) {
     db := setupTestDB(t)
-    authService := services.NewAuthService(db, "test-secret")
+    authService := newTestAuthService(t, db)
 
     email := "test@example.com"
     password := "password123"
-    if err := authService.RegisterUser(email, "testuser", password); err != nil {
+    if err := authService.RegisterUser(email, "testuser", password, ""); err != nil {
         t.Fatalf("Failed to register test user: %v", err)
     }
 
-    tokens, _, err := authService.LoginUser(email, password)
+    tokens, _, err := authService.LoginUser(email, password, services.ClientInfo{})
     if err != nil {
         t.Fatalf("Failed to login test user: %v", err)
     }
@@ -278,7 +268,7 @@ func TestAuthService_RefreshTokens(t *testing.T) {
 
     for _, tt := range tests {
         t.Run(tt.name, func(t *testing.T) {
-            newTokens, exp, err := authService.RefreshTokens(tt.refreshToken)
+            newTokens, exp, err := authService.RefreshTokens(tt.refreshToken, services.ClientInfo{})
             if (err != nil) != tt.wantErr {
                 t.Errorf("RefreshTokens() error = %v, wantErr %v", err, tt.wantErr)
                 return
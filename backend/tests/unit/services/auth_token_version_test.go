@@ -0,0 +1,103 @@
+package services_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bytecast/api/middleware"
+	"bytecast/internal/services"
+)
+
+func newVersionedEngine(t *testing.T, svc *services.AuthService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/protected", middleware.AuthMiddleware(newTestTokenManager(t), svc, nil), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return engine
+}
+
+func requestProtected(engine *gin.Engine, accessToken string) int {
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestTokenVersion_BumpRevokesAccessTokens(t *testing.T) {
+	_, svc := newRotationAuthService(t)
+	engine := newVersionedEngine(t, svc)
+
+	tokens, _, err := svc.LoginUser("rotate", "password123", services.ClientInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, requestProtected(engine, tokens.AccessToken))
+
+	user, err := svc.FindByIdentifier("rotate")
+	require.NoError(t, err)
+	require.NoError(t, svc.BumpTokenVersion(user.ID))
+
+	assert.Equal(t, http.StatusUnauthorized, requestProtected(engine, tokens.AccessToken))
+
+	fresh, _, err := svc.LoginUser("rotate", "password123", services.ClientInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, requestProtected(engine, fresh.AccessToken))
+}
+
+func TestTokenVersion_LogoutAllRevokesAccessTokens(t *testing.T) {
+	_, svc := newRotationAuthService(t)
+	engine := newVersionedEngine(t, svc)
+
+	tokens, _, err := svc.LoginUser("rotate", "password123", services.ClientInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, requestProtected(engine, tokens.AccessToken))
+
+	user, err := svc.FindByIdentifier("rotate")
+	require.NoError(t, err)
+	_, err = svc.RevokeAllSessions(user.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, requestProtected(engine, tokens.AccessToken))
+}
+
+func TestTokenVersion_RejectsDeletedUser(t *testing.T) {
+	db, svc := newRotationAuthService(t)
+	engine := newVersionedEngine(t, svc)
+
+	tokens, _, err := svc.LoginUser("rotate", "password123", services.ClientInfo{})
+	require.NoError(t, err)
+
+	require.NoError(t, db.Exec("DELETE FROM users").Error)
+	assert.Equal(t, http.StatusUnauthorized, requestProtected(engine, tokens.AccessToken))
+}
+
+func TestTokenVersionCache_SweepsExpiredEntries(t *testing.T) {
+	db := newSQLiteDB(t)
+	first := seedUser(t, db, "first")
+	second := seedUser(t, db, "second")
+	cache := services.NewTokenVersionCache(db, 20*time.Millisecond)
+
+	_, err := cache.Get(first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cache.Len())
+
+	time.Sleep(30 * time.Millisecond)
+
+	// Storing a new entry sweeps out the expired one
+	_, err = cache.Get(second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cache.Len())
+
+	// An expired entry for a user that no longer exists is dropped on read
+	require.NoError(t, db.Unscoped().Delete(second).Error)
+	time.Sleep(30 * time.Millisecond)
+	_, err = cache.Get(second.ID)
+	assert.Error(t, err)
+	assert.Equal(t, 0, cache.Len())
+}
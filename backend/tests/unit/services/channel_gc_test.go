@@ -0,0 +1,65 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bytecast/internal/models"
+	"bytecast/internal/services"
+)
+
+func TestChannelGC_Run(t *testing.T) {
+	db := newSQLiteDB(t)
+	owner := seedUser(t, db, "owner")
+	watchlist := seedWatchlist(t, db, owner.ID, "Tech")
+	pubsub := &recordingPubSub{}
+	gc := services.NewChannelGCService(db)
+	gc.SetPubSubService(pubsub)
+
+	active := seedChannel(t, db, watchlist.ID, "UC-active")
+	require.NoError(t, db.Create(&models.HubSubscription{ChannelID: active.YoutubeID, LeaseSeconds: 3600, ExpiresAt: time.Now().Add(time.Hour)}).Error)
+
+	expired := seedChannel(t, db, watchlist.ID, "UC-expired")
+	require.NoError(t, db.Create(&models.HubSubscription{ChannelID: expired.YoutubeID, LeaseSeconds: 3600, ExpiresAt: time.Now().Add(-time.Hour)}).Error)
+
+	seedChannel(t, db, watchlist.ID, "UC-missing")
+
+	orphan := seedChannel(t, db, watchlist.ID, "UC-orphan")
+	require.NoError(t, db.Exec("DELETE FROM watchlist_channels WHERE channel_id = ?", orphan.ID).Error)
+
+	result, err := gc.Run()
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Collected)
+	assert.Equal(t, 2, result.Resubscribed)
+	assert.Equal(t, []string{"UC-orphan"}, pubsub.unsubscribed)
+	assert.ElementsMatch(t, []string{"UC-expired", "UC-missing"}, pubsub.subscribed)
+
+	var videos int64
+	require.NoError(t, db.Model(&models.Video{}).Where("channel_id = ?", orphan.ID).Count(&videos).Error)
+	assert.Zero(t, videos)
+
+	// A second pass has nothing left to collect
+	result, err = gc.Run()
+	require.NoError(t, err)
+	assert.Zero(t, result.Collected)
+}
+
+func TestChannelGC_CollectChannelsSkipsReferenced(t *testing.T) {
+	db := newSQLiteDB(t)
+	owner := seedUser(t, db, "owner")
+	watchlist := seedWatchlist(t, db, owner.ID, "Tech")
+	gc := services.NewChannelGCService(db)
+
+	referenced := seedChannel(t, db, watchlist.ID, "UC-referenced")
+
+	collected, err := gc.CollectChannels([]uint{referenced.ID})
+	require.NoError(t, err)
+	assert.Zero(t, collected)
+
+	var channels int64
+	require.NoError(t, db.Model(&models.Channel{}).Count(&channels).Error)
+	assert.Equal(t, int64(1), channels)
+}
@@ -0,0 +1,107 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bytecast/internal/models"
+	"bytecast/internal/services"
+)
+
+func TestRegistration_OpenByDefault(t *testing.T) {
+	_, auth, _, _ := newAdminService(t)
+
+	assert.Equal(t, services.RegistrationOpen, auth.RegistrationMode())
+	require.NoError(t, auth.RegisterUser("new@example.com", "newuser", "password123", ""))
+}
+
+func TestRegistration_Closed(t *testing.T) {
+	db, auth, svc, admin := newAdminService(t)
+	auth.SetRegistrationMode(services.RegistrationClosed)
+
+	_, code, err := svc.CreateInviteCode(admin.ID, "", 1, nil)
+	require.NoError(t, err)
+
+	// Not even an invite code gets past a closed registration
+	err = auth.RegisterUser("new@example.com", "newuser", "password123", code)
+	assert.ErrorIs(t, err, services.ErrRegistrationClosed)
+
+	_, _, err = auth.LoginWithIdentity(googleIdentity("g-1", "jane@example.com"), services.ClientInfo{})
+	assert.ErrorIs(t, err, services.ErrRegistrationClosed)
+
+	var count int64
+	require.NoError(t, db.Model(&models.User{}).Where("username IN ?", []string{"newuser", "jane"}).Count(&count).Error)
+	assert.Zero(t, count)
+
+	// Existing accounts still sign in
+	_, _, err = auth.LoginUser("rotate", "password123", services.ClientInfo{})
+	assert.NoError(t, err)
+}
+
+func TestRegistration_InviteCodeUses(t *testing.T) {
+	db, auth, svc, admin := newAdminService(t)
+	auth.SetRegistrationMode(services.RegistrationInvite)
+
+	invite, code, err := svc.CreateInviteCode(admin.ID, " team ", 2, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "team", invite.Note)
+	assert.Equal(t, code[:4], invite.Prefix)
+	assert.NotContains(t, invite.CodeHash, code)
+
+	assert.ErrorIs(t, auth.RegisterUser("a@example.com", "usera", "password123", ""), services.ErrInviteCodeRequired)
+	assert.ErrorIs(t, auth.RegisterUser("a@example.com", "usera", "password123", "WRONGCODE"), services.ErrInviteCodeInvalid)
+
+	// Codes are typed by hand, so case and surrounding spaces don't matter
+	require.NoError(t, auth.RegisterUser("a@example.com", "usera", "password123", " "+code+" "))
+
+	// A registration that fails for another reason gives the use back
+	assert.ErrorIs(t, auth.RegisterUser("a@example.com", "userb", "password123", code), services.ErrUserExists)
+
+	var user models.User
+	require.NoError(t, db.Where("username = ?", "usera").First(&user).Error)
+	require.NotNil(t, user.InviteCodeID)
+	assert.Equal(t, invite.ID, *user.InviteCodeID)
+
+	require.NoError(t, auth.RegisterUser("b@example.com", "userb", "password123", code))
+	assert.ErrorIs(t, auth.RegisterUser("c@example.com", "userc", "password123", code), services.ErrInviteCodeInvalid)
+
+	require.NoError(t, db.First(invite, invite.ID).Error)
+	assert.Equal(t, 2, invite.Uses)
+}
+
+func TestRegistration_InviteCodeExpiryAndRevocation(t *testing.T) {
+	_, auth, svc, admin := newAdminService(t)
+	auth.SetRegistrationMode(services.RegistrationInvite)
+
+	past := time.Now().Add(-time.Hour)
+	_, expired, err := svc.CreateInviteCode(admin.ID, "", 1, &past)
+	require.NoError(t, err)
+	assert.ErrorIs(t, auth.RegisterUser("a@example.com", "usera", "password123", expired), services.ErrInviteCodeInvalid)
+
+	revoked, code, err := svc.CreateInviteCode(admin.ID, "", 0, nil)
+	require.NoError(t, err)
+	require.NoError(t, svc.RevokeInviteCode(revoked.ID))
+	assert.ErrorIs(t, svc.RevokeInviteCode(revoked.ID), services.ErrInviteCodeNotFound)
+	assert.ErrorIs(t, auth.RegisterUser("a@example.com", "usera", "password123", code), services.ErrInviteCodeInvalid)
+
+	invites, err := svc.ListInviteCodes()
+	require.NoError(t, err)
+	require.Len(t, invites, 2)
+	assert.Equal(t, revoked.ID, invites[0].ID)
+	assert.NotNil(t, invites[0].RevokedAt)
+}
+
+func TestRegistration_UnlimitedInviteCode(t *testing.T) {
+	_, auth, svc, admin := newAdminService(t)
+	auth.SetRegistrationMode(services.RegistrationInvite)
+
+	_, code, err := svc.CreateInviteCode(admin.ID, "", 0, nil)
+	require.NoError(t, err)
+
+	for _, name := range []string{"usera", "userb", "userc"} {
+		require.NoError(t, auth.RegisterUser(name+"@example.com", name, "password123", code))
+	}
+}
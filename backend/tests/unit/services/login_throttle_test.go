@@ -0,0 +1,196 @@
+package services_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"bytecast/internal/models"
+	"bytecast/internal/services"
+)
+
+var testThrottle = services.LoginThrottle{
+	Window:              time.Hour,
+	BaseDelay:           time.Minute,
+	MaxDelay:            5 * time.Minute,
+	LockoutDuration:     15 * time.Minute,
+	AccountFreeAttempts: 3,
+	AccountLockAfter:    5,
+	IPFreeAttempts:      6,
+	IPLockAfter:         10,
+}
+
+func newThrottledAuthService(t *testing.T) (*gorm.DB, *services.AuthService) {
+	db, svc := newRotationAuthService(t)
+	svc.SetLoginThrottle(testThrottle)
+	return db, svc
+}
+
+// seedFailures records failed attempts as if they happened age ago. userID is
+// nil for identifiers that match no account.
+func seedFailures(t *testing.T, db *gorm.DB, identifier string, userID *uint, ip string, count int, age time.Duration) {
+	for i := 0; i < count; i++ {
+		require.NoError(t, db.Create(&models.LoginAttempt{
+			Identifier: identifier,
+			UserID:     userID,
+			IPAddress:  ip,
+			CreatedAt:  time.Now().Add(-age),
+		}).Error)
+	}
+}
+
+func retryAfter(t *testing.T, err error) time.Duration {
+	var throttled *services.LoginThrottledError
+	require.True(t, errors.As(err, &throttled), "expected a throttled error, got %v", err)
+	assert.ErrorIs(t, err, services.ErrTooManyAttempts)
+	return throttled.RetryAfter
+}
+
+func TestLoginUser_BacksOffAfterFreeAttempts(t *testing.T) {
+	_, svc := newThrottledAuthService(t)
+	client := services.ClientInfo{IPAddress: "192.0.2.1"}
+
+	for i := 0; i < 3; i++ {
+		_, _, err := svc.LoginUser("rotate", "wrong-password", client)
+		assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+	}
+
+	// Even the right password is refused until the delay has passed
+	_, _, err := svc.LoginUser("rotate", "password123", client)
+	wait := retryAfter(t, err)
+	assert.InDelta(t, time.Minute.Seconds(), wait.Seconds(), 5)
+}
+
+func TestLoginUser_DelayDoublesPerFailure(t *testing.T) {
+	db, svc := newThrottledAuthService(t)
+	userID := rotateUserID(t, svc)
+
+	seedFailures(t, db, "rotate", &userID, "", 4, 0)
+
+	_, _, err := svc.LoginUser("rotate", "password123", services.ClientInfo{})
+	assert.InDelta(t, (2 * time.Minute).Seconds(), retryAfter(t, err).Seconds(), 5)
+}
+
+func TestLoginUser_LocksOutAfterThreshold(t *testing.T) {
+	db, svc := newThrottledAuthService(t)
+	userID := rotateUserID(t, svc)
+
+	// Past the longest backoff, but the lockout still applies
+	seedFailures(t, db, "rotate", &userID, "", 5, 10*time.Minute)
+
+	_, _, err := svc.LoginUser("rotate", "password123", services.ClientInfo{})
+	assert.InDelta(t, (5 * time.Minute).Seconds(), retryAfter(t, err).Seconds(), 5)
+}
+
+func TestLoginUser_UnknownAccountIsIndistinguishable(t *testing.T) {
+	_, svc := newThrottledAuthService(t)
+
+	for i := 0; i < 3; i++ {
+		_, _, err := svc.LoginUser("nobody", "wrong-password", services.ClientInfo{})
+		assert.Equal(t, services.ErrInvalidCredentials, err)
+	}
+
+	_, _, err := svc.LoginUser("nobody", "wrong-password", services.ClientInfo{})
+	retryAfter(t, err)
+}
+
+func TestLoginUser_UnknownIdentifierIsCaseInsensitive(t *testing.T) {
+	db, svc := newThrottledAuthService(t)
+
+	seedFailures(t, db, "nobody@example.com", nil, "", 3, 0)
+
+	_, _, err := svc.LoginUser("  Nobody@Example.com", "password123", services.ClientInfo{})
+	retryAfter(t, err)
+}
+
+func TestLoginUser_EmailAndUsernameShareTheBudget(t *testing.T) {
+	_, svc := newThrottledAuthService(t)
+
+	for _, identifier := range []string{"rotate", "rotate@example.com", "rotate"} {
+		_, _, err := svc.LoginUser(identifier, "wrong-password", services.ClientInfo{})
+		assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+	}
+
+	_, _, err := svc.LoginUser("rotate@example.com", "password123", services.ClientInfo{})
+	retryAfter(t, err)
+}
+
+func TestLoginUser_UnknownIdentifierFailuresDoNotLockAccounts(t *testing.T) {
+	db, svc := newThrottledAuthService(t)
+
+	// Recorded while the identifier matched no account
+	seedFailures(t, db, "rotate", nil, "", 5, 0)
+
+	_, _, err := svc.LoginUser("rotate", "password123", services.ClientInfo{})
+	assert.NoError(t, err)
+}
+
+func TestLoginUser_SuccessResetsAccountFailures(t *testing.T) {
+	db, svc := newThrottledAuthService(t)
+	userID := rotateUserID(t, svc)
+
+	seedFailures(t, db, "rotate", &userID, "", 3, 2*time.Minute)
+
+	_, _, err := svc.LoginUser("rotate", "password123", services.ClientInfo{})
+	require.NoError(t, err)
+
+	_, _, err = svc.LoginUser("rotate", "wrong-password", services.ClientInfo{})
+	assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+}
+
+func TestLoginUser_ThrottlesIPAcrossIdentifiers(t *testing.T) {
+	db, svc := newThrottledAuthService(t)
+	client := services.ClientInfo{IPAddress: "192.0.2.7"}
+
+	for i, identifier := range []string{"a", "b", "c", "d", "e", "f"} {
+		seedFailures(t, db, identifier, nil, client.IPAddress, 1, time.Duration(6-i)*time.Second)
+	}
+
+	_, _, err := svc.LoginUser("rotate", "password123", client)
+	retryAfter(t, err)
+
+	// Another address is unaffected
+	_, _, err = svc.LoginUser("rotate", "password123", services.ClientInfo{IPAddress: "192.0.2.8"})
+	assert.NoError(t, err)
+}
+
+func TestLoginUser_RecordsAttempts(t *testing.T) {
+	db, svc := newThrottledAuthService(t)
+	client := services.ClientInfo{IPAddress: "192.0.2.1"}
+
+	_, _, err := svc.LoginUser("rotate", "wrong-password", client)
+	require.ErrorIs(t, err, services.ErrInvalidCredentials)
+	_, _, err = svc.LoginUser("nobody", "wrong-password", client)
+	require.ErrorIs(t, err, services.ErrInvalidCredentials)
+	_, _, err = svc.LoginUser("rotate", "password123", client)
+	require.NoError(t, err)
+
+	var attempts []models.LoginAttempt
+	require.NoError(t, db.Order("id").Find(&attempts).Error)
+	require.Len(t, attempts, 3)
+
+	assert.Equal(t, "rotate", attempts[0].Identifier)
+	assert.NotNil(t, attempts[0].UserID)
+	assert.False(t, attempts[0].Succeeded)
+	assert.Equal(t, "192.0.2.1", attempts[0].IPAddress)
+
+	assert.Nil(t, attempts[1].UserID)
+	assert.False(t, attempts[1].Succeeded)
+
+	assert.True(t, attempts[2].Succeeded)
+}
+
+func TestPurgeLoginAttempts(t *testing.T) {
+	db, svc := newThrottledAuthService(t)
+
+	seedFailures(t, db, "rotate", nil, "", 2, 48*time.Hour)
+	seedFailures(t, db, "rotate", nil, "", 1, 0)
+
+	purged, err := svc.PurgeLoginAttempts(time.Now().Add(-24 * time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+}
@@ -0,0 +1,147 @@
+package services_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bytecast/api/middleware"
+	"bytecast/internal/models"
+	"bytecast/internal/services"
+)
+
+func newPersonalTokenEngine(t *testing.T, svc *services.AuthService, personal middleware.PersonalTokenLookup) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	handler := func(c *gin.Context) { c.Status(http.StatusOK) }
+	auth := middleware.AuthMiddleware(newTestTokenManager(t), svc, personal)
+	engine.GET("/protected", auth, handler)
+	engine.POST("/protected", auth, handler)
+	return engine
+}
+
+func requestWithToken(engine *gin.Engine, method, token string) int {
+	req := httptest.NewRequest(method, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w.Code
+}
+
+func rotateUserID(t *testing.T, svc *services.AuthService) uint {
+	user, err := svc.FindByIdentifier("rotate")
+	require.NoError(t, err)
+	return user.ID
+}
+
+func TestPersonalToken_CreateAndAuthenticate(t *testing.T) {
+	db, svc := newRotationAuthService(t)
+	userID := rotateUserID(t, svc)
+
+	pat, raw, err := svc.CreatePersonalToken(userID, " backup script ", models.TokenScopeRead, nil)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(raw, services.PersonalTokenPrefix))
+	assert.True(t, strings.HasPrefix(raw, pat.Prefix))
+	assert.Equal(t, "backup script", pat.Name)
+
+	// Only the hash is stored
+	var stored models.PersonalAccessToken
+	require.NoError(t, db.First(&stored, pat.ID).Error)
+	assert.NotEqual(t, raw, stored.TokenHash)
+	assert.Nil(t, stored.LastUsedAt)
+
+	gotUser, scope, err := svc.AuthenticatePersonalToken(raw)
+	require.NoError(t, err)
+	assert.Equal(t, userID, gotUser)
+	assert.Equal(t, string(models.TokenScopeRead), scope)
+
+	require.NoError(t, db.First(&stored, pat.ID).Error)
+	assert.NotNil(t, stored.LastUsedAt)
+
+	_, _, err = svc.AuthenticatePersonalToken(raw + "x")
+	assert.ErrorIs(t, err, services.ErrTokenInvalid)
+}
+
+func TestPersonalToken_Expiry(t *testing.T) {
+	_, svc := newRotationAuthService(t)
+	userID := rotateUserID(t, svc)
+
+	expired := time.Now().Add(-time.Minute)
+	_, raw, err := svc.CreatePersonalToken(userID, "old", models.TokenScopeWrite, &expired)
+	require.NoError(t, err)
+
+	_, _, err = svc.AuthenticatePersonalToken(raw)
+	assert.ErrorIs(t, err, services.ErrTokenInvalid)
+}
+
+func TestPersonalToken_Revoke(t *testing.T) {
+	_, svc := newRotationAuthService(t)
+	userID := rotateUserID(t, svc)
+
+	pat, raw, err := svc.CreatePersonalToken(userID, "ci", models.TokenScopeWrite, nil)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, svc.RevokePersonalToken(userID+1, pat.ID), services.ErrPersonalTokenNotFound)
+	require.NoError(t, svc.RevokePersonalToken(userID, pat.ID))
+	assert.ErrorIs(t, svc.RevokePersonalToken(userID, pat.ID), services.ErrPersonalTokenNotFound)
+
+	_, _, err = svc.AuthenticatePersonalToken(raw)
+	assert.ErrorIs(t, err, services.ErrTokenRevoked)
+
+	tokens, err := svc.GetPersonalTokens(userID)
+	require.NoError(t, err)
+	assert.Empty(t, tokens)
+}
+
+func TestPersonalToken_Validation(t *testing.T) {
+	_, svc := newRotationAuthService(t)
+	userID := rotateUserID(t, svc)
+
+	_, _, err := svc.CreatePersonalToken(userID, "admin", models.TokenScope("admin"), nil)
+	assert.ErrorIs(t, err, services.ErrInvalidTokenScope)
+
+	for i := 0; i < 25; i++ {
+		_, _, err := svc.CreatePersonalToken(userID, "token", models.TokenScopeRead, nil)
+		require.NoError(t, err)
+	}
+	_, _, err = svc.CreatePersonalToken(userID, "one too many", models.TokenScopeRead, nil)
+	assert.ErrorIs(t, err, services.ErrPersonalTokenLimit)
+}
+
+func TestPersonalToken_MiddlewareScopes(t *testing.T) {
+	_, svc := newRotationAuthService(t)
+	userID := rotateUserID(t, svc)
+	engine := newPersonalTokenEngine(t, svc, svc)
+
+	_, readToken, err := svc.CreatePersonalToken(userID, "read", models.TokenScopeRead, nil)
+	require.NoError(t, err)
+	_, writeToken, err := svc.CreatePersonalToken(userID, "write", models.TokenScopeWrite, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, requestWithToken(engine, http.MethodGet, readToken))
+	assert.Equal(t, http.StatusForbidden, requestWithToken(engine, http.MethodPost, readToken))
+	assert.Equal(t, http.StatusOK, requestWithToken(engine, http.MethodPost, writeToken))
+	assert.Equal(t, http.StatusUnauthorized, requestWithToken(engine, http.MethodGet, services.PersonalTokenPrefix+"unknown"))
+
+	// JWTs keep working next to personal tokens
+	tokens, _, err := svc.LoginUser("rotate", "password123", services.ClientInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, requestWithToken(engine, http.MethodPost, tokens.AccessToken))
+}
+
+func TestPersonalToken_RejectedWithoutLookup(t *testing.T) {
+	_, svc := newRotationAuthService(t)
+	userID := rotateUserID(t, svc)
+	engine := newPersonalTokenEngine(t, svc, nil)
+
+	_, raw, err := svc.CreatePersonalToken(userID, "write", models.TokenScopeWrite, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, requestWithToken(engine, http.MethodGet, raw))
+}
@@ -0,0 +1,135 @@
+package services_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"bytecast/api/handler"
+	"bytecast/api/middleware"
+	"bytecast/internal/models"
+	"bytecast/internal/services"
+)
+
+func newPreferencesService(t *testing.T) (*gorm.DB, *services.WatchlistService, *services.PreferencesService, *models.User) {
+	db, watchlists, _, owner := newOrganizeService(t)
+	return db, watchlists, services.NewPreferencesService(db, watchlists), owner
+}
+
+func TestPreferences_DefaultsWithoutRow(t *testing.T) {
+	_, _, svc, owner := newPreferencesService(t)
+
+	prefs, err := svc.GetPreferences(owner.ID)
+	require.NoError(t, err)
+	assert.Equal(t, owner.ID, prefs.UserID)
+	assert.Equal(t, "UTC", prefs.Timezone)
+	assert.Equal(t, models.FeedSortNewest, prefs.FeedSort)
+	assert.Equal(t, models.ThemeSystem, prefs.Theme)
+	assert.Nil(t, prefs.DefaultWatchlistID)
+	assert.True(t, prefs.Notifications.WatchlistInvitations)
+}
+
+func TestPreferences_PartialUpdate(t *testing.T) {
+	_, _, svc, owner := newPreferencesService(t)
+
+	timezone := "Europe/Berlin"
+	_, err := svc.UpdatePreferences(owner.ID, services.PreferencesUpdate{Timezone: &timezone})
+	require.NoError(t, err)
+
+	theme := models.ThemeDark
+	off := false
+	prefs, err := svc.UpdatePreferences(owner.ID, services.PreferencesUpdate{Theme: &theme, NotifyWatchlistInvitations: &off})
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", prefs.Timezone)
+	assert.Equal(t, models.ThemeDark, prefs.Theme)
+	assert.False(t, prefs.Notifications.WatchlistInvitations)
+
+	stored, err := svc.GetPreferences(owner.ID)
+	require.NoError(t, err)
+	assert.Equal(t, prefs.Timezone, stored.Timezone)
+	assert.Equal(t, prefs.Theme, stored.Theme)
+	assert.False(t, stored.Notifications.WatchlistInvitations)
+}
+
+func TestPreferences_DefaultWatchlistAccess(t *testing.T) {
+	db, _, svc, owner := newPreferencesService(t)
+	other := seedUser(t, db, "other")
+	own := seedWatchlist(t, db, owner.ID, "Mine")
+	foreign := seedWatchlist(t, db, other.ID, "Theirs")
+	shared := seedWatchlist(t, db, other.ID, "Shared")
+	require.NoError(t, db.Create(&models.WatchlistMember{WatchlistID: shared.ID, UserID: owner.ID, Role: models.WatchlistRoleViewer}).Error)
+
+	prefs, err := svc.UpdatePreferences(owner.ID, services.PreferencesUpdate{DefaultWatchlistID: &own.ID})
+	require.NoError(t, err)
+	require.NotNil(t, prefs.DefaultWatchlistID)
+	assert.Equal(t, own.ID, *prefs.DefaultWatchlistID)
+
+	_, err = svc.UpdatePreferences(owner.ID, services.PreferencesUpdate{DefaultWatchlistID: &shared.ID})
+	require.NoError(t, err)
+
+	_, err = svc.UpdatePreferences(owner.ID, services.PreferencesUpdate{DefaultWatchlistID: &foreign.ID})
+	assert.ErrorIs(t, err, services.ErrWatchlistNotFound)
+
+	zero := uint(0)
+	prefs, err = svc.UpdatePreferences(owner.ID, services.PreferencesUpdate{DefaultWatchlistID: &zero})
+	require.NoError(t, err)
+	assert.Nil(t, prefs.DefaultWatchlistID)
+}
+
+func TestPreferences_PurgeClearsDefaultWatchlist(t *testing.T) {
+	db, watchlists, svc, owner := newPreferencesService(t)
+	trashed := seedWatchlist(t, db, owner.ID, "Old")
+	_, err := svc.UpdatePreferences(owner.ID, services.PreferencesUpdate{DefaultWatchlistID: &trashed.ID})
+	require.NoError(t, err)
+
+	require.NoError(t, db.Delete(trashed).Error)
+	purged, err := watchlists.PurgeTrashedWatchlists(time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 1, purged)
+
+	prefs, err := svc.GetPreferences(owner.ID)
+	require.NoError(t, err)
+	assert.Nil(t, prefs.DefaultWatchlistID)
+}
+
+func TestPreferences_PatchValidation(t *testing.T) {
+	_, _, svc, owner := newPreferencesService(t)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(middleware.ErrorHandler())
+	authenticate := func(c *gin.Context) { c.Set("user_id", owner.ID) }
+	handler.NewPreferencesHandler(svc).RegisterRoutes(engine, authenticate, nil)
+
+	patch := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/api/v1/users/me/preferences", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnprocessableEntity, patch(`{"timezone":"Mars/Olympus"}`).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, patch(`{"theme":"neon"}`).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, patch(`{"feed_sort":"random"}`).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, patch(`{"locale":"not a locale"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, patch(`{"default_watchlist_id":999}`).Code)
+
+	w := patch(`{"timezone":"America/New_York","locale":"pt-BR","notifications":{"new_videos":true}}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var prefs models.UserPreferences
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &prefs))
+	assert.Equal(t, "America/New_York", prefs.Timezone)
+	assert.Equal(t, "pt-BR", prefs.Locale)
+	assert.True(t, prefs.Notifications.NewVideos)
+	assert.Equal(t, models.ThemeSystem, prefs.Theme)
+}
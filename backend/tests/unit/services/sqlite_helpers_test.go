@@ -0,0 +1,95 @@
+package services_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"bytecast/internal/models"
+)
+
+// sqliteWatchlist mirrors models.Watchlist without the Postgres-only color check constraint
+type sqliteWatchlist struct {
+	gorm.Model
+	UserID      uint    `gorm:"index;not null"`
+	Name        string  `gorm:"size:255;not null"`
+	Description string  `gorm:"type:text"`
+	Color       string  `gorm:"size:7;not null"`
+	ShareSlug   *string `gorm:"uniqueIndex;size:32"`
+	SharedAt    *time.Time
+	Channels    []*models.Channel `gorm:"many2many:watchlist_channels;foreignKey:ID;joinForeignKey:WatchlistID;References:ID;joinReferences:ChannelID"`
+	Videos      []*models.Video   `gorm:"many2many:watchlist_videos;foreignKey:ID;joinForeignKey:WatchlistID;References:ID;joinReferences:VideoID"`
+}
+
+func (sqliteWatchlist) TableName() string {
+	return "watchlists"
+}
+
+// newSQLiteDB opens a fresh in-memory database with the watchlist schema
+func newSQLiteDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(
+		&models.User{},
+		&models.Channel{},
+		&sqliteWatchlist{},
+		&models.Video{},
+		&models.WatchlistMember{},
+		&models.WatchlistInvitation{},
+		&models.HubSubscription{},
+		&models.RevokedToken{},
+		&models.Session{},
+		&models.UserToken{},
+		&models.LoginAttempt{},
+		&models.RecoveryCode{},
+		&models.UserIdentity{},
+		&models.PersonalAccessToken{},
+		&models.InviteCode{},
+		&models.AuditEvent{},
+		&models.UserPreferences{},
+	)
+	require.NoError(t, err)
+
+	return db
+}
+
+func seedUser(t *testing.T, db *gorm.DB, username string) *models.User {
+	user := &models.User{
+		Email:        username + "@example.com",
+		Username:     username,
+		PasswordHash: "hashedpassword",
+	}
+	require.NoError(t, db.Create(user).Error)
+	return user
+}
+
+func seedWatchlist(t *testing.T, db *gorm.DB, userID uint, name string) *sqliteWatchlist {
+	watchlist := &sqliteWatchlist{UserID: userID, Name: name, Color: "#3b82f6"}
+	require.NoError(t, db.Create(watchlist).Error)
+	return watchlist
+}
+
+// seedChannel creates a channel with one video and attaches both to the watchlist
+func seedChannel(t *testing.T, db *gorm.DB, watchlistID uint, youtubeID string) *models.Channel {
+	channel := &models.Channel{YoutubeID: youtubeID, Title: "Channel " + youtubeID}
+	require.NoError(t, db.Create(channel).Error)
+
+	video := &models.Video{YoutubeID: "v-" + youtubeID, ChannelID: channel.ID, Title: "Video " + youtubeID}
+	require.NoError(t, db.Create(video).Error)
+
+	require.NoError(t, db.Exec("INSERT INTO watchlist_channels (watchlist_id, channel_id) VALUES (?, ?)", watchlistID, channel.ID).Error)
+	require.NoError(t, db.Exec("INSERT INTO watchlist_videos (watchlist_id, video_id) VALUES (?, ?)", watchlistID, video.ID).Error)
+
+	return channel
+}
+
+func countRows(t *testing.T, db *gorm.DB, table string, watchlistID uint) int64 {
+	var count int64
+	require.NoError(t, db.Table(table).Where("watchlist_id = ?", watchlistID).Count(&count).Error)
+	return count
+}
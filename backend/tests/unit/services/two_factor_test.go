@@ -0,0 +1,203 @@
+package services_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bytecast/internal/models"
+	"bytecast/internal/services"
+	"bytecast/internal/totp"
+)
+
+// enrollTOTP enables two-factor authentication for the "rotate" user and
+// returns the secret and recovery codes.
+func enrollTOTP(t *testing.T, auth *services.AuthService, svc *services.AccountService) (string, []string) {
+	user, err := auth.FindByIdentifier("rotate")
+	require.NoError(t, err)
+
+	enrollment, err := svc.BeginTOTPEnrollment(user.ID)
+	require.NoError(t, err)
+	assert.Contains(t, enrollment.URI, "secret="+enrollment.Secret)
+
+	code, err := totp.Code(enrollment.Secret, time.Now())
+	require.NoError(t, err)
+	codes, err := svc.ConfirmTOTPEnrollment(user.ID, code)
+	require.NoError(t, err)
+
+	return enrollment.Secret, codes
+}
+
+// nextCode returns a code for the following time step, which is still within
+// the accepted drift but newer than any code used so far.
+func nextCode(t *testing.T, secret string) string {
+	code, err := totp.Code(secret, time.Now().Add(totp.Period))
+	require.NoError(t, err)
+	return code
+}
+
+func loginChallenge(t *testing.T, auth *services.AuthService) string {
+	_, _, err := auth.LoginUser("rotate", "password123", services.ClientInfo{})
+	var challenge *services.TwoFactorRequiredError
+	require.True(t, errors.As(err, &challenge), "expected a two-factor challenge, got %v", err)
+	assert.ErrorIs(t, err, services.ErrTwoFactorRequired)
+	return challenge.ChallengeToken
+}
+
+func TestTwoFactor_LoginWithTOTP(t *testing.T) {
+	_, auth, svc, mail := newAccountService(t)
+	secret, codes := enrollTOTP(t, auth, svc)
+	assert.Len(t, codes, 10)
+	assert.Equal(t, "Two-factor authentication enabled", mail.sent[len(mail.sent)-1].Subject)
+
+	challenge := loginChallenge(t, auth)
+
+	stale, err := totp.Code(secret, time.Now().Add(-10*totp.Period))
+	require.NoError(t, err)
+	_, _, _, err = auth.CompleteTwoFactorLogin(challenge, stale, services.ClientInfo{})
+	assert.ErrorIs(t, err, services.ErrInvalidTwoFactorCode)
+
+	code := nextCode(t, secret)
+	tokens, _, user, err := auth.CompleteTwoFactorLogin(challenge, code, services.ClientInfo{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.Equal(t, "rotate", user.Username)
+
+	// A code cannot be replayed
+	_, _, _, err = auth.CompleteTwoFactorLogin(loginChallenge(t, auth), code, services.ClientInfo{})
+	assert.ErrorIs(t, err, services.ErrInvalidTwoFactorCode)
+}
+
+func TestTwoFactor_RecoveryCodesAreSingleUse(t *testing.T) {
+	_, auth, svc, _ := newAccountService(t)
+	_, codes := enrollTOTP(t, auth, svc)
+	assert.Regexp(t, `^[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}$`, codes[0])
+
+	challenge := loginChallenge(t, auth)
+
+	_, _, _, err := auth.CompleteTwoFactorLogin(challenge, " "+codes[0]+" ", services.ClientInfo{})
+	require.NoError(t, err)
+
+	_, _, _, err = auth.CompleteTwoFactorLogin(challenge, codes[0], services.ClientInfo{})
+	assert.ErrorIs(t, err, services.ErrInvalidTwoFactorCode)
+
+	user, err := auth.FindByIdentifier("rotate")
+	require.NoError(t, err)
+	status, err := svc.TwoFactorStatus(user.ID)
+	require.NoError(t, err)
+	assert.True(t, status.Enabled)
+	assert.Equal(t, int64(9), status.RecoveryCodesRemaining)
+
+	// Regenerating invalidates the old codes
+	fresh, err := svc.RegenerateRecoveryCodes(user.ID, "password123")
+	require.NoError(t, err)
+	_, _, _, err = auth.CompleteTwoFactorLogin(challenge, codes[1], services.ClientInfo{})
+	assert.ErrorIs(t, err, services.ErrInvalidTwoFactorCode)
+	_, _, _, err = auth.CompleteTwoFactorLogin(challenge, fresh[0], services.ClientInfo{})
+	assert.NoError(t, err)
+}
+
+func TestTwoFactor_EnrollmentErrors(t *testing.T) {
+	_, auth, svc, _ := newAccountService(t)
+	user, err := auth.FindByIdentifier("rotate")
+	require.NoError(t, err)
+
+	_, err = svc.ConfirmTOTPEnrollment(user.ID, "123456")
+	assert.ErrorIs(t, err, services.ErrTOTPEnrollmentMissing)
+
+	enrollment, err := svc.BeginTOTPEnrollment(user.ID)
+	require.NoError(t, err)
+	code, err := totp.Code(enrollment.Secret, time.Now().Add(-10*totp.Period))
+	require.NoError(t, err)
+	_, err = svc.ConfirmTOTPEnrollment(user.ID, code)
+	assert.ErrorIs(t, err, services.ErrInvalidTwoFactorCode)
+
+	// Until confirmed, logins are not affected
+	_, _, err = auth.LoginUser("rotate", "password123", services.ClientInfo{})
+	assert.NoError(t, err)
+
+	_, err = svc.RegenerateRecoveryCodes(user.ID, "password123")
+	assert.ErrorIs(t, err, services.ErrTwoFactorNotEnabled)
+
+	enrollTOTP(t, auth, svc)
+	_, err = svc.BeginTOTPEnrollment(user.ID)
+	assert.ErrorIs(t, err, services.ErrTwoFactorEnabled)
+}
+
+func TestTwoFactor_ChallengeValidation(t *testing.T) {
+	db, auth, svc, _ := newAccountService(t)
+	secret, _ := enrollTOTP(t, auth, svc)
+
+	_, _, _, err := auth.CompleteTwoFactorLogin("not-a-token", nextCode(t, secret), services.ClientInfo{})
+	assert.ErrorIs(t, err, services.ErrTokenInvalid)
+
+	// Signing out everywhere voids outstanding challenges
+	challenge := loginChallenge(t, auth)
+	user, err := auth.FindByIdentifier("rotate")
+	require.NoError(t, err)
+	require.NoError(t, auth.BumpTokenVersion(user.ID))
+
+	_, _, _, err = auth.CompleteTwoFactorLogin(challenge, nextCode(t, secret), services.ClientInfo{})
+	assert.ErrorIs(t, err, services.ErrTokenInvalid)
+
+	var attempts int64
+	require.NoError(t, db.Model(&models.LoginAttempt{}).Count(&attempts).Error)
+	assert.Zero(t, attempts, "password step of a two-factor login is not recorded on its own")
+}
+
+func TestTwoFactor_FailedCodesAreThrottled(t *testing.T) {
+	_, auth, svc, _ := newAccountService(t)
+	auth.SetLoginThrottle(testThrottle)
+	secret, _ := enrollTOTP(t, auth, svc)
+
+	challenge := loginChallenge(t, auth)
+	for i := 0; i < 3; i++ {
+		_, _, _, err := auth.CompleteTwoFactorLogin(challenge, "not-a-code", services.ClientInfo{})
+		assert.ErrorIs(t, err, services.ErrInvalidTwoFactorCode)
+	}
+
+	_, _, _, err := auth.CompleteTwoFactorLogin(challenge, nextCode(t, secret), services.ClientInfo{})
+	assert.ErrorIs(t, err, services.ErrTooManyAttempts)
+}
+
+func TestTwoFactor_FailuresShareTheAccountBudget(t *testing.T) {
+	_, auth, svc, _ := newAccountService(t)
+	auth.SetLoginThrottle(testThrottle)
+	enrollTOTP(t, auth, svc)
+
+	_, _, err := auth.LoginUser("rotate@example.com", "wrong-password", services.ClientInfo{})
+	require.ErrorIs(t, err, services.ErrInvalidCredentials)
+
+	challenge := loginChallenge(t, auth)
+	for i := 0; i < 2; i++ {
+		_, _, _, err := auth.CompleteTwoFactorLogin(challenge, "not-a-code", services.ClientInfo{})
+		assert.ErrorIs(t, err, services.ErrInvalidTwoFactorCode)
+	}
+
+	// One password failure by email and two code failures use up the free attempts
+	_, _, err = auth.LoginUser("rotate@example.com", "password123", services.ClientInfo{})
+	assert.ErrorIs(t, err, services.ErrTooManyAttempts)
+}
+
+func TestTwoFactor_Disable(t *testing.T) {
+	db, auth, svc, mail := newAccountService(t)
+	enrollTOTP(t, auth, svc)
+	user, err := auth.FindByIdentifier("rotate")
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, svc.DisableTwoFactor(user.ID, "wrong-password"), services.ErrIncorrectPassword)
+	require.NoError(t, svc.DisableTwoFactor(user.ID, "password123"))
+	assert.Equal(t, "Two-factor authentication disabled", mail.sent[len(mail.sent)-1].Subject)
+	assert.ErrorIs(t, svc.DisableTwoFactor(user.ID, "password123"), services.ErrTwoFactorNotEnabled)
+
+	var codes int64
+	require.NoError(t, db.Model(&models.RecoveryCode{}).Count(&codes).Error)
+	assert.Zero(t, codes)
+
+	tokens, _, err := auth.LoginUser("rotate", "password123", services.ClientInfo{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+}
@@ -0,0 +1,119 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bytecast/internal/models"
+	"bytecast/internal/services"
+)
+
+// addMember invites the user with the given role and accepts on their behalf
+func addMember(t *testing.T, svc *services.WatchlistService, watchlistID, ownerID uint, member *models.User, role models.WatchlistRole) {
+	invitation, err := svc.InviteMember(watchlistID, ownerID, member.Username, role)
+	require.NoError(t, err)
+	require.NoError(t, svc.RespondToInvitation(invitation.ID, member.ID, true))
+}
+
+func TestWatchlistRoles_Authorization(t *testing.T) {
+	db := newSQLiteDB(t)
+	svc := services.NewWatchlistService(db, nil, nil)
+	owner := seedUser(t, db, "owner")
+	editor := seedUser(t, db, "editor")
+	viewer := seedUser(t, db, "viewer")
+	stranger := seedUser(t, db, "stranger")
+	watchlist := seedWatchlist(t, db, owner.ID, "Team")
+
+	addMember(t, svc, watchlist.ID, owner.ID, editor, models.WatchlistRoleEditor)
+	addMember(t, svc, watchlist.ID, owner.ID, viewer, models.WatchlistRoleViewer)
+
+	tests := []struct {
+		name      string
+		userID    uint
+		readErr   error
+		updateErr error
+		deleteErr error
+	}{
+		{name: "Owner", userID: owner.ID},
+		{name: "Editor", userID: editor.ID, deleteErr: services.ErrNotAuthorized},
+		{name: "Viewer", userID: viewer.ID, updateErr: services.ErrNotAuthorized, deleteErr: services.ErrNotAuthorized},
+		{name: "Stranger", userID: stranger.ID, readErr: services.ErrWatchlistNotFound, updateErr: services.ErrWatchlistNotFound, deleteErr: services.ErrWatchlistNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.GetWatchlist(watchlist.ID, tt.userID)
+			assert.ErrorIs(t, err, tt.readErr)
+
+			_, err = svc.UpdateWatchlist(watchlist.ID, tt.userID, "Renamed", "", "#FF5733")
+			assert.ErrorIs(t, err, tt.updateErr)
+
+			if tt.deleteErr != nil {
+				assert.ErrorIs(t, svc.DeleteWatchlist(watchlist.ID, tt.userID), tt.deleteErr)
+			}
+		})
+	}
+}
+
+func TestWatchlistRoles_SharedWatchlistsAreListed(t *testing.T) {
+	db := newSQLiteDB(t)
+	svc := services.NewWatchlistService(db, nil, nil)
+	owner := seedUser(t, db, "owner")
+	viewer := seedUser(t, db, "viewer")
+	seedWatchlist(t, db, viewer.ID, "Own")
+	shared := seedWatchlist(t, db, owner.ID, "Shared")
+
+	addMember(t, svc, shared.ID, owner.ID, viewer, models.WatchlistRoleViewer)
+
+	watchlists, err := svc.GetUserWatchlists(viewer.ID)
+	require.NoError(t, err)
+	require.Len(t, watchlists, 2)
+	assert.Equal(t, models.WatchlistRoleOwner, watchlists[0].Role)
+	assert.Equal(t, "Shared", watchlists[1].Name)
+	assert.Equal(t, models.WatchlistRoleViewer, watchlists[1].Role)
+}
+
+func TestWatchlistInvitations(t *testing.T) {
+	db := newSQLiteDB(t)
+	svc := services.NewWatchlistService(db, nil, nil)
+	owner := seedUser(t, db, "owner")
+	invitee := seedUser(t, db, "invitee")
+	watchlist := seedWatchlist(t, db, owner.ID, "Team")
+
+	_, err := svc.InviteMember(watchlist.ID, owner.ID, "nobody", models.WatchlistRoleViewer)
+	assert.ErrorIs(t, err, services.ErrInviteeNotFound)
+
+	_, err = svc.InviteMember(watchlist.ID, owner.ID, owner.Email, models.WatchlistRoleViewer)
+	assert.ErrorIs(t, err, services.ErrInvalidInvitation)
+
+	_, err = svc.InviteMember(watchlist.ID, invitee.ID, owner.Email, models.WatchlistRoleViewer)
+	assert.ErrorIs(t, err, services.ErrWatchlistNotFound)
+
+	invitation, err := svc.InviteMember(watchlist.ID, owner.ID, invitee.Email, models.WatchlistRoleEditor)
+	require.NoError(t, err)
+
+	pending, err := svc.GetPendingInvitations(invitee.ID)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "Team", pending[0].Watchlist.Name)
+
+	// Only the invitee can respond
+	assert.ErrorIs(t, svc.RespondToInvitation(invitation.ID, owner.ID, true), services.ErrInvitationNotFound)
+	require.NoError(t, svc.RespondToInvitation(invitation.ID, invitee.ID, true))
+
+	_, err = svc.InviteMember(watchlist.ID, owner.ID, invitee.Username, models.WatchlistRoleViewer)
+	assert.ErrorIs(t, err, services.ErrAlreadyMember)
+
+	members, err := svc.GetMembers(watchlist.ID, invitee.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Equal(t, models.WatchlistRoleOwner, members[0].Role)
+	assert.Equal(t, models.WatchlistRoleEditor, members[1].Role)
+
+	// Members can leave, after which they lose access
+	require.NoError(t, svc.RemoveMember(watchlist.ID, invitee.ID, invitee.ID))
+	_, err = svc.GetWatchlist(watchlist.ID, invitee.ID)
+	assert.ErrorIs(t, err, services.ErrWatchlistNotFound)
+}
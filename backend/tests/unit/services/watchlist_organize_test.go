@@ -0,0 +1,102 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"bytecast/internal/models"
+	"bytecast/internal/services"
+)
+
+// recordingPubSub records WebSub calls so tests can assert none were made
+type recordingPubSub struct {
+	subscribed   []string
+	unsubscribed []string
+}
+
+func (r *recordingPubSub) SubscribeToChannel(channelID string) error {
+	r.subscribed = append(r.subscribed, channelID)
+	return nil
+}
+
+func (r *recordingPubSub) UnsubscribeFromChannel(channelID string) error {
+	r.unsubscribed = append(r.unsubscribed, channelID)
+	return nil
+}
+
+func newOrganizeService(t *testing.T) (*gorm.DB, *services.WatchlistService, *recordingPubSub, *models.User) {
+	db := newSQLiteDB(t)
+	svc := services.NewWatchlistService(db, nil, nil)
+	pubsub := &recordingPubSub{}
+	svc.SetPubSubService(pubsub)
+
+	gc := services.NewChannelGCService(db)
+	gc.SetPubSubService(pubsub)
+	svc.SetChannelGC(gc)
+
+	return db, svc, pubsub, seedUser(t, db, "owner")
+}
+
+func TestDuplicateWatchlist(t *testing.T) {
+	db, svc, pubsub, owner := newOrganizeService(t)
+	source := seedWatchlist(t, db, owner.ID, "Tech")
+	seedChannel(t, db, source.ID, "UC1")
+	seedChannel(t, db, source.ID, "UC2")
+
+	duplicate, err := svc.DuplicateWatchlist(source.ID, owner.ID, "")
+	require.NoError(t, err)
+	assert.Equal(t, "Tech (copy)", duplicate.Name)
+	assert.Equal(t, int64(2), countRows(t, db, "watchlist_channels", duplicate.ID))
+	assert.Equal(t, int64(2), countRows(t, db, "watchlist_videos", duplicate.ID))
+	assert.Empty(t, pubsub.subscribed)
+}
+
+func TestMergeWatchlists(t *testing.T) {
+	db, svc, pubsub, owner := newOrganizeService(t)
+	source := seedWatchlist(t, db, owner.ID, "Source")
+	target := seedWatchlist(t, db, owner.ID, "Target")
+	seedChannel(t, db, source.ID, "UC1")
+	seedChannel(t, db, target.ID, "UC2")
+
+	_, err := svc.MergeWatchlists(source.ID, source.ID, owner.ID)
+	assert.ErrorIs(t, err, services.ErrSameWatchlist)
+
+	merged, err := svc.MergeWatchlists(source.ID, target.ID, owner.ID)
+	require.NoError(t, err)
+	assert.Equal(t, target.ID, merged.ID)
+	assert.Equal(t, int64(2), countRows(t, db, "watchlist_channels", target.ID))
+	assert.Equal(t, int64(2), countRows(t, db, "watchlist_videos", target.ID))
+
+	_, err = svc.GetWatchlist(source.ID, owner.ID)
+	assert.ErrorIs(t, err, services.ErrWatchlistNotFound)
+
+	var videos int64
+	require.NoError(t, db.Model(&models.Video{}).Count(&videos).Error)
+	assert.Equal(t, int64(2), videos)
+	assert.Empty(t, pubsub.unsubscribed)
+}
+
+func TestMoveChannel(t *testing.T) {
+	db, svc, pubsub, owner := newOrganizeService(t)
+	source := seedWatchlist(t, db, owner.ID, "Source")
+	target := seedWatchlist(t, db, owner.ID, "Target")
+	seedChannel(t, db, source.ID, "UC1")
+	seedChannel(t, db, source.ID, "UC2")
+
+	err := svc.MoveChannel(source.ID, target.ID, owner.ID, "UC-missing")
+	assert.ErrorIs(t, err, services.ErrChannelNotFound)
+
+	require.NoError(t, svc.MoveChannel(source.ID, target.ID, owner.ID, "UC1"))
+	assert.Equal(t, int64(1), countRows(t, db, "watchlist_channels", source.ID))
+	assert.Equal(t, int64(1), countRows(t, db, "watchlist_videos", source.ID))
+	assert.Equal(t, int64(1), countRows(t, db, "watchlist_channels", target.ID))
+	assert.Equal(t, int64(1), countRows(t, db, "watchlist_videos", target.ID))
+
+	var channels int64
+	require.NoError(t, db.Model(&models.Channel{}).Count(&channels).Error)
+	assert.Equal(t, int64(2), channels)
+	assert.Empty(t, pubsub.unsubscribed)
+}
@@ -0,0 +1,69 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bytecast/internal/services"
+)
+
+func TestShareLink_CreateAndResolve(t *testing.T) {
+	db := newSQLiteDB(t)
+	svc := services.NewWatchlistService(db, nil, nil)
+	owner := seedUser(t, db, "owner")
+	watchlist := seedWatchlist(t, db, owner.ID, "Curated")
+	seedChannel(t, db, watchlist.ID, "UC1")
+
+	shared, err := svc.CreateShareLink(watchlist.ID, owner.ID)
+	require.NoError(t, err)
+	require.NotNil(t, shared.ShareSlug)
+
+	public, err := svc.GetSharedWatchlist(*shared.ShareSlug)
+	require.NoError(t, err)
+	assert.Equal(t, "Curated", public.Name)
+	assert.Len(t, public.Channels, 1)
+
+	_, err = svc.CreateShareLink(watchlist.ID, owner.ID+1)
+	assert.ErrorIs(t, err, services.ErrWatchlistNotFound)
+}
+
+func TestShareLink_Revoke(t *testing.T) {
+	db := newSQLiteDB(t)
+	svc := services.NewWatchlistService(db, nil, nil)
+	owner := seedUser(t, db, "owner")
+	watchlist := seedWatchlist(t, db, owner.ID, "Curated")
+
+	shared, err := svc.CreateShareLink(watchlist.ID, owner.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.RevokeShareLink(watchlist.ID, owner.ID))
+
+	_, err = svc.GetSharedWatchlist(*shared.ShareSlug)
+	assert.ErrorIs(t, err, services.ErrShareLinkNotFound)
+}
+
+func TestShareLink_Clone(t *testing.T) {
+	db := newSQLiteDB(t)
+	svc := services.NewWatchlistService(db, nil, nil)
+	owner := seedUser(t, db, "owner")
+	other := seedUser(t, db, "other")
+	watchlist := seedWatchlist(t, db, owner.ID, "Curated")
+	seedChannel(t, db, watchlist.ID, "UC1")
+	seedChannel(t, db, watchlist.ID, "UC2")
+
+	shared, err := svc.CreateShareLink(watchlist.ID, owner.ID)
+	require.NoError(t, err)
+
+	clone, err := svc.CloneSharedWatchlist(*shared.ShareSlug, other.ID)
+	require.NoError(t, err)
+	assert.Equal(t, other.ID, clone.UserID)
+	assert.Equal(t, "Curated", clone.Name)
+	assert.Nil(t, clone.ShareSlug)
+	assert.Equal(t, int64(2), countRows(t, db, "watchlist_channels", clone.ID))
+	assert.Equal(t, int64(2), countRows(t, db, "watchlist_videos", clone.ID))
+
+	// The source is left untouched
+	assert.Equal(t, int64(2), countRows(t, db, "watchlist_channels", watchlist.ID))
+}
@@ -7,7 +7,6 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
 	"bytecast/configs"
@@ -76,15 +75,10 @@ func (TestWatchlist) TableName() string {
 	return "watchlists"
 }
 
+// setupWatchlistTestDB uses the shared sqlite schema so the watchlists table
+// has every column WatchlistService writes
 func setupWatchlistTestDB(t *testing.T) *gorm.DB {
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	require.NoError(t, err)
-
-	// Run migrations for User, TestWatchlist, and Channel models
-	err = db.AutoMigrate(&models.User{}, &TestWatchlist{}, &models.Channel{})
-	require.NoError(t, err)
-
-	return db
+	return newSQLiteDB(t)
 }
 
 func createTestUser(t *testing.T, db *gorm.DB) *models.User {
@@ -129,13 +123,7 @@ func createMockConfig() *configs.Config {
 
 // Create a watchlist service with a mock YouTube service for testing
 func createWatchlistService(db *gorm.DB, config *configs.Config) *services.WatchlistService {
-	service := services.NewWatchlistService(db, config)
-	
-	// Replace the YouTube service with our mock
-	mockYouTube := &MockYouTubeService{}
-	service.SetYouTubeService(mockYouTube)
-	
-	return service
+	return services.NewWatchlistService(db, config, &MockYouTubeService{})
 }
 
 func TestCreateWatchlist(t *testing.T) {
@@ -0,0 +1,68 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bytecast/internal/models"
+	"bytecast/internal/services"
+)
+
+func TestTrash_DeleteAndRestore(t *testing.T) {
+	db, svc, _, owner := newOrganizeService(t)
+	watchlist := seedWatchlist(t, db, owner.ID, "Tech")
+	seedChannel(t, db, watchlist.ID, "UC1")
+
+	require.NoError(t, svc.DeleteWatchlist(watchlist.ID, owner.ID))
+
+	trashed, err := svc.GetTrashedWatchlists(owner.ID)
+	require.NoError(t, err)
+	require.Len(t, trashed, 1)
+	assert.True(t, trashed[0].DeletedAt.Valid)
+
+	restored, err := svc.RestoreWatchlist(watchlist.ID, owner.ID)
+	require.NoError(t, err)
+	assert.False(t, restored.DeletedAt.Valid)
+
+	channels, err := svc.GetChannelsInWatchlist(watchlist.ID, owner.ID)
+	require.NoError(t, err)
+	assert.Len(t, channels, 1)
+
+	_, err = svc.RestoreWatchlist(watchlist.ID, owner.ID)
+	assert.ErrorIs(t, err, services.ErrWatchlistNotFound)
+}
+
+func TestTrash_PurgeCleansUpUnusedChannels(t *testing.T) {
+	db, svc, pubsub, owner := newOrganizeService(t)
+	trashed := seedWatchlist(t, db, owner.ID, "Old")
+	kept := seedWatchlist(t, db, owner.ID, "Kept")
+	seedChannel(t, db, trashed.ID, "UC-orphan")
+	shared := seedChannel(t, db, trashed.ID, "UC-shared")
+	require.NoError(t, db.Exec("INSERT INTO watchlist_channels (watchlist_id, channel_id) VALUES (?, ?)", kept.ID, shared.ID).Error)
+
+	require.NoError(t, svc.DeleteWatchlist(trashed.ID, owner.ID))
+
+	// Nothing is old enough yet
+	purged, err := svc.PurgeTrashedWatchlists(time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, purged)
+
+	purged, err = svc.PurgeTrashedWatchlists(time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	var remaining int64
+	require.NoError(t, db.Unscoped().Model(&models.Watchlist{}).Where("id = ?", trashed.ID).Count(&remaining).Error)
+	assert.Zero(t, remaining)
+	assert.Zero(t, countRows(t, db, "watchlist_channels", trashed.ID))
+
+	assert.Equal(t, []string{"UC-orphan"}, pubsub.unsubscribed)
+
+	var channels []models.Channel
+	require.NoError(t, db.Find(&channels).Error)
+	require.Len(t, channels, 1)
+	assert.Equal(t, "UC-shared", channels[0].YoutubeID)
+}
@@ -1,9 +1,11 @@
-package services
+package services_test
 
 import (
 	"encoding/xml"
 	"testing"
 	"time"
+
+	"bytecast/internal/services"
 )
 
 func TextNotificationParse(t *testing.T) {
@@ -29,7 +31,7 @@ func TextNotificationParse(t *testing.T) {
   </entry>
 </feed>`
 
-	var feed Feed
+	var feed services.Feed
 	err := xml.Unmarshal([]byte(sampleXML), &feed)
 	if err != nil {
 		t.Fatalf("Failed to parse XML: %v", err)
@@ -0,0 +1,181 @@
+package token_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bytecast/configs"
+	"bytecast/internal/token"
+)
+
+func testClaims() *token.Claims {
+	now := time.Now()
+	return &token.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "token-id",
+			Subject:   "1",
+			Issuer:    "bytecast",
+			Audience:  jwt.ClaimStrings{"bytecast-api"},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+		},
+		Type: token.TypeAccess,
+	}
+}
+
+func newManager(keys *token.KeySet) *token.Manager {
+	return token.NewManager(keys, "bytecast", "bytecast-api", 30*time.Second)
+}
+
+func TestKeySet_SignAndParse(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	for name, privateKey := range map[string]interface{}{"EdDSA": edKey, "RS256": rsaKey} {
+		t.Run(name, func(t *testing.T) {
+			signing, err := token.NewSigningKey("", privateKey)
+			require.NoError(t, err)
+			assert.Equal(t, name, signing.Method.Alg())
+
+			keys, err := token.NewKeySet(signing)
+			require.NoError(t, err)
+
+			signed, err := keys.Sign(testClaims())
+			require.NoError(t, err)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(signed, &token.Claims{})
+			require.NoError(t, err)
+			assert.Equal(t, signing.ID, parsed.Header["kid"])
+
+			claims, err := newManager(keys).Parse(signed, token.TypeAccess)
+			require.NoError(t, err)
+			userID, err := claims.UserID()
+			require.NoError(t, err)
+			assert.Equal(t, uint(1), userID)
+		})
+	}
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	_, oldPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, newPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	oldSigning, err := token.NewSigningKey("old", oldPrivate)
+	require.NoError(t, err)
+	oldKeys, err := token.NewKeySet(oldSigning)
+	require.NoError(t, err)
+	oldToken, err := oldKeys.Sign(testClaims())
+	require.NoError(t, err)
+
+	newSigning, err := token.NewSigningKey("new", newPrivate)
+	require.NoError(t, err)
+	retired, err := token.NewVerificationKey("old", oldPrivate.Public())
+	require.NoError(t, err)
+
+	rotated, err := token.NewKeySet(newSigning, retired)
+	require.NoError(t, err)
+
+	_, err = newManager(rotated).Parse(oldToken, token.TypeAccess)
+	assert.NoError(t, err)
+
+	newOnly, err := token.NewKeySet(newSigning)
+	require.NoError(t, err)
+	_, err = newManager(newOnly).Parse(oldToken, token.TypeAccess)
+	assert.ErrorIs(t, err, token.ErrInvalidToken)
+
+	jwks := rotated.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "new", jwks.Keys[0].KeyID)
+	assert.Equal(t, "OKP", jwks.Keys[0].KeyType)
+	assert.Equal(t, "old", jwks.Keys[1].KeyID)
+}
+
+func TestKeySet_RejectsAlgorithmMismatch(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	signing, err := token.NewSigningKey("rsa", rsaKey)
+	require.NoError(t, err)
+	keys, err := token.NewKeySet(signing)
+	require.NoError(t, err)
+
+	// An HS256 token claiming the RSA kid, signed with the public key bytes
+	publicDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+	forged.Header["kid"] = "rsa"
+	forgedString, err := forged.SignedString(publicDER)
+	require.NoError(t, err)
+
+	_, err = newManager(keys).Parse(forgedString, token.TypeAccess)
+	assert.ErrorIs(t, err, token.ErrInvalidToken)
+}
+
+func TestKeySet_HMACAcceptsTokensWithoutKid(t *testing.T) {
+	keys, err := token.NewKeySet(token.NewHMACKey([]byte("test-secret")))
+	require.NoError(t, err)
+
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims()).SignedString([]byte("test-secret"))
+	require.NoError(t, err)
+
+	_, err = newManager(keys).Parse(legacy, token.TypeAccess)
+	assert.NoError(t, err)
+	assert.Empty(t, keys.JWKS().Keys)
+}
+
+func TestLoadKeySet_FromPEMFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	_, current, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	currentDER, err := x509.MarshalPKCS8PrivateKey(current)
+	require.NoError(t, err)
+	privatePath := filepath.Join(dir, "current.pem")
+	require.NoError(t, os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: currentDER}), 0o600))
+
+	retired, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	retiredDER, err := x509.MarshalPKIXPublicKey(&retired.PublicKey)
+	require.NoError(t, err)
+	publicPath := filepath.Join(dir, "retired.pem")
+	require.NoError(t, os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: retiredDER}), 0o600))
+
+	secret := "an-hs256-secret-that-is-long-enough"
+	keys, err := token.LoadKeySet(&configs.JWT{
+		Algorithm:        "EdDSA",
+		Secret:           secret,
+		PrivateKeyFile:   privatePath,
+		KeyID:            "current",
+		VerificationKeys: []string{"retired=" + publicPath},
+	})
+	require.NoError(t, err)
+
+	kids := []string{}
+	for _, key := range keys.JWKS().Keys {
+		kids = append(kids, key.KeyID)
+	}
+	assert.Equal(t, []string{"current", "retired"}, kids)
+
+	// Tokens signed with the previous shared secret remain valid
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims()).SignedString([]byte(secret))
+	require.NoError(t, err)
+	_, err = newManager(keys).Parse(legacy, token.TypeAccess)
+	assert.NoError(t, err)
+
+	_, err = token.LoadKeySet(&configs.JWT{Algorithm: "RS256", PrivateKeyFile: privatePath})
+	assert.Error(t, err)
+}
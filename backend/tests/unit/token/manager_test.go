@@ -0,0 +1,84 @@
+package token_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bytecast/internal/token"
+)
+
+func newHMACKeySet(t *testing.T) *token.KeySet {
+	keys, err := token.NewKeySet(token.NewHMACKey([]byte("test-secret")))
+	require.NoError(t, err)
+	return keys
+}
+
+func TestManager_IssueAndParse(t *testing.T) {
+	manager := newManager(newHMACKeySet(t))
+
+	signed, issued, err := manager.Issue(42, token.TypeRefresh, time.Hour, token.Claims{FamilyID: "family"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, issued.ID)
+
+	claims, err := manager.Parse(signed, token.TypeRefresh)
+	require.NoError(t, err)
+	assert.Equal(t, "42", claims.Subject)
+	assert.Equal(t, issued.ID, claims.ID)
+	assert.Equal(t, "family", claims.FamilyID)
+	assert.NotNil(t, claims.IssuedAt)
+	assert.NotNil(t, claims.NotBefore)
+
+	_, err = manager.Parse(signed, token.TypeAccess)
+	assert.ErrorIs(t, err, token.ErrInvalidToken)
+}
+
+func TestManager_ValidatesIssuerAndAudience(t *testing.T) {
+	keys := newHMACKeySet(t)
+	manager := newManager(keys)
+
+	otherIssuer, _, err := token.NewManager(keys, "someone-else", "bytecast-api", 0).Issue(1, token.TypeAccess, time.Hour, token.Claims{})
+	require.NoError(t, err)
+	_, err = manager.Parse(otherIssuer, token.TypeAccess)
+	assert.ErrorIs(t, err, token.ErrInvalidToken)
+
+	otherAudience, _, err := token.NewManager(keys, "bytecast", "another-api", 0).Issue(1, token.TypeAccess, time.Hour, token.Claims{})
+	require.NoError(t, err)
+	_, err = manager.Parse(otherAudience, token.TypeAccess)
+	assert.ErrorIs(t, err, token.ErrInvalidToken)
+}
+
+func TestManager_Leeway(t *testing.T) {
+	keys := newHMACKeySet(t)
+	manager := newManager(keys)
+
+	sign := func(expiredFor time.Duration) string {
+		claims := testClaims()
+		claims.IssuedAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+		claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-expiredFor))
+		signed, err := keys.Sign(claims)
+		require.NoError(t, err)
+		return signed
+	}
+
+	_, err := manager.Parse(sign(10*time.Second), token.TypeAccess)
+	assert.NoError(t, err)
+
+	_, err = manager.Parse(sign(time.Minute), token.TypeAccess)
+	assert.ErrorIs(t, err, token.ErrInvalidToken)
+}
+
+func TestManager_RequiresSubject(t *testing.T) {
+	keys := newHMACKeySet(t)
+
+	claims := testClaims()
+	claims.Subject = "not-a-user"
+	signed, err := keys.Sign(claims)
+	require.NoError(t, err)
+
+	_, err = newManager(keys).Parse(signed, token.TypeAccess)
+	assert.ErrorIs(t, err, token.ErrInvalidToken)
+}
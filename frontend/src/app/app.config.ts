@@ -1,7 +1,11 @@
 import { ApplicationConfig, provideZoneChangeDetection } from "@angular/core";
 import { provideRouter } from "@angular/router";
 import { provideAnimations } from "@angular/platform-browser/animations";
-import { provideHttpClient, withInterceptors } from "@angular/common/http";
+import {
+  provideHttpClient,
+  withInterceptors,
+  withXsrfConfiguration,
+} from "@angular/common/http";
 
 import { routes } from "./app.routes";
 import { authInterceptor } from "./core/http";
@@ -11,6 +15,13 @@ export const appConfig: ApplicationConfig = {
     provideZoneChangeDetection({ eventCoalescing: true }),
     provideRouter(routes),
     provideAnimations(),
-    provideHttpClient(withInterceptors([authInterceptor])),
+    provideHttpClient(
+      withInterceptors([authInterceptor]),
+      // Echo the CSRF cookie set next to the refresh token on refresh and logout
+      withXsrfConfiguration({
+        cookieName: "csrf_token",
+        headerName: "X-CSRF-Token",
+      })
+    ),
   ],
 };
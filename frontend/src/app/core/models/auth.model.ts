@@ -6,8 +6,12 @@ export interface RegisterRequest {
   email: string;
   username: string;
   password: string;
+  invite_code?: string;
 }
 
+// Whether the instance lets anyone sign up, only people with an invite code, or nobody
+export type RegistrationMode = "open" | "invite" | "closed";
+
 export interface LoginRequest {
   identifier: string;
   password: string;
@@ -11,7 +11,12 @@ import {
 } from "rxjs";
 import { Router } from "@angular/router";
 import { toast } from "ngx-sonner";
-import { AuthResponse, LoginRequest, RegisterRequest } from "../models/auth.model";
+import {
+  AuthResponse,
+  LoginRequest,
+  RegisterRequest,
+  RegistrationMode,
+} from "../models/auth.model";
 
 @Injectable({
   providedIn: "root",
@@ -33,6 +38,12 @@ export class AuthService {
     this.isAuthenticatedSubject.next(this.hasValidToken());
   }
 
+  getRegistrationMode(): Observable<RegistrationMode> {
+    return this.http
+      .get<{ mode: RegistrationMode }>(`${this.API_URL}/registration`)
+      .pipe(map((response) => response.mode));
+  }
+
   register(data: RegisterRequest): Observable<void> {
     return this.http.post<AuthResponse>(`${this.API_URL}/register`, data).pipe(
       tap((response) => {
@@ -94,7 +105,8 @@ export class AuthService {
       map(() => void 0),
       catchError((error) => {
         console.error("Token refresh failed:", error);
-        if (error.status === 401) {
+        // 403 means the CSRF cookie is missing, e.g. for sessions started before it existed
+        if (error.status === 401 || error.status === 403) {
           this.clearToken();
           this.isAuthenticatedSubject.next(false);
           this.router.navigate(["/"]);
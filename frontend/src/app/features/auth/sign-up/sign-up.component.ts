@@ -19,6 +19,7 @@ import { HlmToasterComponent } from "@spartan-ng/ui-sonner-helm";
 import { passwordMatchValidator } from "./sign-up.validators";
 
 import { AuthService } from "../../../core/services";
+import { RegistrationMode } from "../../../core/models/auth.model";
 import { AuthLayoutComponent } from "../../../layout";
 
 @Component({
@@ -46,12 +47,14 @@ export class SignUpComponent {
   submitted = false;
   isLoading = false;
   error: string | null = null;
+  registrationMode: RegistrationMode = "open";
 
   focusedFields: { [key: string]: boolean } = {
     username: false,
     email: false,
     password: false,
     confirmPassword: false,
+    inviteCode: false,
   };
 
   constructor(private fb: FormBuilder) {
@@ -70,11 +73,25 @@ export class SignUpComponent {
           ],
         ],
         confirmPassword: ["", [Validators.required]],
+        inviteCode: ["", [Validators.maxLength(64)]],
       },
       {
         validators: passwordMatchValidator,
       }
     );
+
+    // Registration stays open in the form if the mode cannot be loaded; the API still enforces it
+    this.authService.getRegistrationMode().subscribe({
+      next: (mode) => {
+        this.registrationMode = mode;
+        if (mode === "invite") {
+          const inviteCode = this.signUpForm.get("inviteCode");
+          inviteCode?.addValidators(Validators.required);
+          inviteCode?.updateValueAndValidity();
+        }
+      },
+      error: () => {},
+    });
   }
 
   showError(fieldName: string): boolean {
@@ -97,10 +114,19 @@ export class SignUpComponent {
   onSubmit() {
     this.submitted = true;
 
+    if (this.registrationMode === "closed") {
+      toast.error("Registration is closed");
+      return;
+    }
+
     if (this.signUpForm.valid) {
       this.isLoading = true;
 
-      const { confirmPassword, ...registrationData } = this.signUpForm.value;
+      const { confirmPassword, inviteCode, ...registrationData } =
+        this.signUpForm.value;
+      if (this.registrationMode === "invite") {
+        registrationData.invite_code = inviteCode;
+      }
 
       this.authService.register(registrationData).subscribe({
         next: () => {
@@ -117,6 +143,8 @@ export class SignUpComponent {
             this.signUpForm.get("email")?.setErrors({ exists: true });
           } else if (message.includes("username")) {
             this.signUpForm.get("username")?.setErrors({ taken: true });
+          } else if (message.includes("invite code")) {
+            this.signUpForm.get("inviteCode")?.setErrors({ invalid: true });
           }
         },
       });
@@ -132,6 +160,8 @@ export class SignUpComponent {
           "Password must contain at least one letter, one number, and one special character";
       } else if (this.signUpForm.get("email")?.hasError("email")) {
         errorMessage = "Please enter a valid email address";
+      } else if (this.signUpForm.get("inviteCode")?.hasError("required")) {
+        errorMessage = "An invite code is required to sign up";
       }
 
       toast.error(errorMessage);